@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fllaca/scheriff/pkg/baseline"
+	"github.com/spf13/cobra"
+)
+
+var (
+	baselineCmd = &cobra.Command{
+		Use:   "baseline",
+		Short: "Manage baselines of known findings",
+		Long: `Manage baselines of known findings
+
+A baseline records the findings of a validation, so that the validations using it with the --baseline flag only report (and fail on) new findings. Findings are identified by their file, kind, namespace/name and message`,
+	}
+
+	baselineCreateCmd = &cobra.Command{
+		Use:   "create <file>",
+		Short: "Validate the manifests and record their current findings in a baseline file",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			options.input = cmd.InOrStdin()
+			options.output = cmd.OutOrStdout()
+			options.errOutput = cmd.ErrOrStderr()
+			err := applyConfig(&options, cmd.Flags())
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error loading configuration: %s\n", err)
+				os.Exit(1)
+			}
+			err = runBaselineCreate(options, args[0], cmd.ErrOrStderr())
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error creating baseline %s: %s\n", args[0], err)
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func init() {
+	addValidateFlags(baselineCreateCmd.Flags(), &options)
+	baselineCmd.AddCommand(baselineCreateCmd)
+	rootCmd.AddCommand(baselineCmd)
+}
+
+// runBaselineCreate validates the manifests as runValidate does, and writes the errors and warnings found to the baseline file
+func runBaselineCreate(opts validateOptions, file string, out io.Writer) error {
+	// the findings of a previous baseline must be recorded too
+	opts.baseline = ""
+	exitCode, totalResults := runValidate(opts)
+	if exitCode != 0 && !containsSeverity(totalResults, opts.strict) {
+		return fmt.Errorf("the validation failed")
+	}
+	knownFindings := baseline.New(totalResults)
+	err := knownFindings.Save(file)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Baseline with %d findings written to %s\n", len(knownFindings.Findings), file)
+	return nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fllaca/scheriff/pkg/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scheriff-baseline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baselineFile := filepath.Join(dir, "baseline.json")
+
+	out := &bytes.Buffer{}
+	opts := validateOptions{
+		filenames:              []string{"testdata/manifests/warn_error.yaml"},
+		openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+		output:                 &bytes.Buffer{},
+		errOutput:              &bytes.Buffer{},
+	}
+	err = runBaselineCreate(opts, baselineFile, out)
+	assert.NoError(t, err)
+	assert.Equal(t, "Baseline with 2 findings written to "+baselineFile+"\n", out.String())
+
+	// the findings of the baseline are hidden, while the new ones are still reported
+	errOut := &bytes.Buffer{}
+	opts.filenames = []string{"testdata/manifests/warn_error.yaml", "testdata/manifests/unknown_kind.yaml"}
+	opts.baseline = baselineFile
+	opts.strict = true
+	opts.errOutput = errOut
+	exitCode, results := runValidate(opts)
+
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, []validate.ValidationResult{
+		{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "example-unknown-kind", Namespace: "example", Kind: "example.io/v1/UnknownCRD", Source: "testdata/manifests/unknown_kind.yaml", Document: 0, Line: 1, Column: 1},
+	}, results)
+
+	// fixed findings are reported so that the baseline can be updated
+	opts.filenames = []string{"testdata/manifests/unknown_kind.yaml"}
+	opts.output = errOut
+	exitCode, _ = runValidate(opts)
+
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, errOut.String(), "0 findings hidden by the baseline "+baselineFile+"\n2 findings of the baseline are no longer found, update it with 'scheriff baseline create'\n")
+}
+
+func TestBaselineCreateValidationError(t *testing.T) {
+	opts := validateOptions{
+		filenames:              []string{"testdata/manifests/warn_error.yaml"},
+		openApiSchemaFilenames: []string{"testdata/schemas/missing.json"},
+		output:                 &bytes.Buffer{},
+		errOutput:              &bytes.Buffer{},
+	}
+	err := runBaselineCreate(opts, filepath.Join(os.TempDir(), "scheriff-baseline-not-created.json"), &bytes.Buffer{})
+	assert.EqualError(t, err, "the validation failed")
+}
+
+func TestBaselineLoadError(t *testing.T) {
+	errOut := &bytes.Buffer{}
+	opts := validateOptions{
+		filenames:              []string{"testdata/manifests/warn_error.yaml"},
+		openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+		baseline:               "testdata/missing-baseline.json",
+		output:                 errOut,
+	}
+	exitCode, results := runValidate(opts)
+
+	assert.Equal(t, 1, exitCode)
+	assert.Empty(t, results)
+	assert.Contains(t, errOut.String(), "Error loading baseline: open testdata/missing-baseline.json: no such file or directory\n")
+}
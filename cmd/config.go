@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"github.com/fllaca/scheriff/pkg/config"
+	"github.com/spf13/pflag"
+)
+
+// applyConfig sets the options that weren't given as flags from the configuration file: the one given with --config,
+// or the one found from the working directory upwards, if any
+func applyConfig(opts *validateOptions, flags *pflag.FlagSet) error {
+	if opts.configFile == "" {
+		configFile, err := config.Find(".")
+		if err != nil || configFile == "" {
+			return err
+		}
+		opts.configFile = configFile
+	}
+	projectConfig, err := config.Load(opts.configFile)
+	if err != nil {
+		return err
+	}
+	overrides, err := projectConfig.RuleOverrides()
+	if err != nil {
+		return err
+	}
+	opts.overrides = overrides
+
+	setStrings := func(flag string, option *[]string, value []string) {
+		if !flags.Changed(flag) && len(value) > 0 {
+			*option = value
+		}
+	}
+	setStrings("schema", &opts.openApiSchemaFilenames, projectConfig.Schemas)
+	setStrings("kubernetes-version", &opts.kubernetesVersions, projectConfig.KubernetesVersions)
+	setStrings("crd", &opts.crds, projectConfig.Crds)
+	setStrings("filename", &opts.filenames, projectConfig.Files)
+	setStrings("include", &opts.include, projectConfig.Include)
+	setStrings("exclude", &opts.exclude, projectConfig.Exclude)
+	setStrings("allow-reference", &opts.allowedReferences, projectConfig.AllowedReferences)
+	if !flags.Changed("recursive") && projectConfig.Recursive {
+		opts.recursive = true
+	}
+	if !flags.Changed("strict") && projectConfig.Strict {
+		opts.strict = true
+	}
+	if !flags.Changed("require-namespace") && projectConfig.RequireNamespace {
+		opts.requireNamespace = true
+	}
+	if !flags.Changed("check-references") && projectConfig.CheckReferences {
+		opts.checkReferences = true
+	}
+	if !flags.Changed("check-selectors") && projectConfig.CheckSelectors {
+		opts.checkSelectors = true
+	}
+	if !flags.Changed("check-duplicates") && projectConfig.CheckDuplicates {
+		opts.checkDuplicates = true
+	}
+	if !flags.Changed("default-namespace") && projectConfig.DefaultNamespace != "" {
+		opts.defaultNamespace = projectConfig.DefaultNamespace
+	}
+	if !flags.Changed("output") && projectConfig.Output != "" {
+		opts.outputFormat = projectConfig.Output
+	}
+	if !flags.Changed("baseline") && projectConfig.Baseline != "" {
+		opts.baseline = projectConfig.Baseline
+	}
+	return nil
+}
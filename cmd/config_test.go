@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fllaca/scheriff/pkg/report"
+	"github.com/fllaca/scheriff/pkg/validate"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFlagSet(opts *validateOptions) *pflag.FlagSet {
+	flags := pflag.NewFlagSet("scheriff", pflag.ContinueOnError)
+	flags.StringArrayVarP(&opts.openApiSchemaFilenames, "schema", "s", []string{}, "")
+	flags.StringArrayVarP(&opts.filenames, "filename", "f", []string{}, "")
+	flags.BoolVarP(&opts.strict, "strict", "S", false, "")
+	flags.StringVarP(&opts.outputFormat, "output", "o", report.FormatText, "")
+	return flags
+}
+
+func TestApplyConfig(t *testing.T) {
+	opts := validateOptions{configFile: "testdata/config/.scheriff.yaml"}
+	err := applyConfig(&opts, newTestFlagSet(&opts))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"testdata/schemas/versions/1.17.json"}, opts.openApiSchemaFilenames)
+	assert.Equal(t, []string{"testdata/manifests/test_ignore", "testdata/manifests/unknown_kind.yaml"}, opts.filenames)
+	assert.Equal(t, []string{"**/Chart.yaml"}, opts.exclude)
+	assert.True(t, opts.recursive)
+	assert.True(t, opts.strict)
+	assert.Equal(t, report.FormatJSON, opts.outputFormat)
+	assert.Equal(t, []validate.RuleOverride{{Kind: "example.io/*/UnknownCRD", Rule: validate.RuleUnknownKind, Severity: validate.SeverityOK}}, opts.overrides)
+
+	// the unknown kind would be a warning (so a failure in strict mode) without the overrides of the configuration
+	opts.output = &bytes.Buffer{}
+	opts.errOutput = &bytes.Buffer{}
+	exitCode, results := runValidate(opts)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, []validate.ValidationResult{
+		{Message: "valid", Severity: validate.SeverityOK, Name: "app", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/test_ignore/app/configmap.yaml", Document: 0},
+		{Message: "valid", Severity: validate.SeverityOK, Name: "values", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/test_ignore/app/values-resource.yaml", Document: 0},
+		{Message: "valid", Severity: validate.SeverityOK, Name: "root", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/test_ignore/configmap.yaml", Document: 0},
+		{Message: "valid", Severity: validate.SeverityOK, Name: "example-unknown-kind", Namespace: "example", Kind: "example.io/v1/UnknownCRD", Source: "testdata/manifests/unknown_kind.yaml", Document: 0},
+	}, results)
+}
+
+func TestApplyConfigFlagsPrecedence(t *testing.T) {
+	opts := validateOptions{configFile: "testdata/config/.scheriff.yaml"}
+	flags := newTestFlagSet(&opts)
+	err := flags.Parse([]string{"-f", "testdata/manifests/unknown_kind.yaml", "-o", "text"})
+	assert.NoError(t, err)
+
+	err = applyConfig(&opts, flags)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"testdata/manifests/unknown_kind.yaml"}, opts.filenames)
+	assert.Equal(t, report.FormatText, opts.outputFormat)
+	assert.Equal(t, []string{"testdata/schemas/versions/1.17.json"}, opts.openApiSchemaFilenames)
+}
+
+func TestApplyConfigError(t *testing.T) {
+	opts := validateOptions{configFile: "testdata/config/missing.yaml"}
+	err := applyConfig(&opts, newTestFlagSet(&opts))
+
+	assert.EqualError(t, err, "open testdata/config/missing.yaml: no such file or directory")
+}
@@ -1,27 +1,67 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 
+	"github.com/fllaca/scheriff/pkg/baseline"
+	"github.com/fllaca/scheriff/pkg/config"
 	"github.com/fllaca/scheriff/pkg/fs"
 	"github.com/fllaca/scheriff/pkg/kubernetes"
+	"github.com/fllaca/scheriff/pkg/report"
+	"github.com/fllaca/scheriff/pkg/store"
 	"github.com/fllaca/scheriff/pkg/utils"
 	"github.com/fllaca/scheriff/pkg/validate"
 	"github.com/spf13/cobra"
-
-	"github.com/gookit/color"
+	"github.com/spf13/pflag"
 )
 
 type validateOptions struct {
-	filenames             []string
-	crds                  []string
-	openApiSchemaFilename string
-	recursive             bool
-	strict                bool
-	input                 io.Reader
+	filenames              []string
+	crds                   []string
+	openApiSchemaFilenames []string
+	kubernetesVersions     []string
+	schemaStore            string
+	recursive              bool
+	include                []string
+	exclude                []string
+	strict                 bool
+	verbose                bool
+	checkDeprecations      bool
+	requireNamespace       bool
+	checkReferences        bool
+	allowedReferences      []string
+	checkSelectors         bool
+	checkDuplicates        bool
+	defaultNamespace       string
+	jobs                   int
+	outputFormat           string
+	configFile             string
+	baseline               string
+	overrides              []validate.RuleOverride
+	input                  io.Reader
+	output                 io.Writer
+	errOutput              io.Writer
+}
+
+func (opts validateOptions) stdout() io.Writer {
+	if opts.output == nil {
+		return os.Stdout
+	}
+	return opts.output
+}
+
+func (opts validateOptions) stderr() io.Writer {
+	if opts.errOutput == nil {
+		return os.Stderr
+	}
+	return opts.errOutput
 }
 
 var (
@@ -34,6 +74,13 @@ var (
 Schema Sheriff performs offline validation of Kubernetes configuration manifests by checking them against OpenApi schemas. No connectivity to the Kubernetes cluster is needed`,
 		Run: func(cmd *cobra.Command, args []string) {
 			options.input = cmd.InOrStdin()
+			options.output = cmd.OutOrStdout()
+			options.errOutput = cmd.ErrOrStderr()
+			err := applyConfig(&options, cmd.Flags())
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error loading configuration: %s\n", err)
+				os.Exit(1)
+			}
 			exitCode, _ := runValidate(options)
 			os.Exit(exitCode)
 		},
@@ -43,14 +90,31 @@ Schema Sheriff performs offline validation of Kubernetes configuration manifests
 )
 
 func init() {
-	rootCmd.PersistentFlags().StringArrayVarP(&options.filenames, "filename", "f", []string{}, "(required) file or directories that contain the configuration to be validated")
-	// TODO support OpenApi V3 input
-	rootCmd.PersistentFlags().StringVarP(&options.openApiSchemaFilename, "schema", "s", "", "(required) Kubernetes OpenAPI V2 schema to validate against")
-	rootCmd.PersistentFlags().BoolVarP(&options.recursive, "recursive", "R", false, "process the directory used in -f, --filename recursively. Useful when you want to manage related manifests organized within the same directory.")
-	rootCmd.PersistentFlags().StringArrayVarP(&options.crds, "crd", "c", []string{}, "files or directories that contain CustomResourceDefinitions to be used for validation")
-	rootCmd.PersistentFlags().BoolVarP(&options.strict, "strict", "S", false, "return exit code 1 not only on errors but also when warnings are encountered.")
-	rootCmd.MarkPersistentFlagRequired("filename")
-	rootCmd.MarkPersistentFlagRequired("schema")
+	addValidateFlags(rootCmd.Flags(), &options)
+	rootCmd.Flags().StringVar(&options.baseline, "baseline", "", "baseline file created with 'scheriff baseline create': the findings in the baseline are hidden, so only new findings are reported and make the validation fail")
+}
+
+// addValidateFlags adds the flags of the validation options, shared by the commands that validate manifests
+func addValidateFlags(flags *pflag.FlagSet, opts *validateOptions) {
+	flags.StringArrayVarP(&opts.filenames, "filename", "f", []string{}, "file or directories that contain the configuration to be validated (required unless set in the configuration file)")
+	flags.StringArrayVarP(&opts.openApiSchemaFilenames, "schema", "s", []string{}, "Kubernetes OpenAPI schema to validate against: an OpenAPI V2 file, an OpenAPI V3 document, a directory of OpenAPI V3 group-version documents, a directory of OpenAPI V2 files named by Kubernetes version, or a bundle compiled with 'scheriff schema compile'. Can be used several times to validate against each of the schemas")
+	flags.BoolVarP(&opts.recursive, "recursive", "R", false, "process the directory used in -f, --filename recursively. Useful when you want to manage related manifests organized within the same directory.")
+	flags.StringArrayVar(&opts.include, "include", []string{}, "glob pattern of the files to validate in the directories used in -f, --filename (ie: '**/*.yaml'), relative to them. '**' matches any number of directories. Can be used several times")
+	flags.StringArrayVar(&opts.exclude, "exclude", []string{}, "glob pattern of the files or directories to skip in the directories used in -f, --filename (ie: '**/kustomization.yaml'), besides the ones listed in .scheriffignore files. Can be used several times")
+	flags.StringArrayVar(&opts.kubernetesVersions, "kubernetes-version", []string{}, "Kubernetes version (ie: 1.24.3) whose schema and CRDs are taken from the local schema store, as an alternative to -s, --schema. Can be used several times to validate against each of the versions")
+	flags.StringArrayVarP(&opts.crds, "crd", "c", []string{}, "files or directories that contain CustomResourceDefinitions to be used for validation")
+	flags.BoolVarP(&opts.strict, "strict", "S", false, "return exit code 1 not only on errors but also when warnings are encountered.")
+	flags.BoolVarP(&opts.verbose, "verbose", "v", false, "include the details of schema violations (failing schema and offending value) in the results.")
+	flags.BoolVar(&opts.checkDeprecations, "check-deprecations", false, "report resources using deprecated API versions (warning) or API versions removed in the Kubernetes version of the schema (error).")
+	flags.BoolVar(&opts.requireNamespace, "require-namespace", false, "report the resources of namespaced kinds without namespace (the resources of cluster scoped kinds with namespace are always reported).")
+	flags.BoolVar(&opts.checkReferences, "check-references", false, "report the ConfigMaps, Secrets, PersistentVolumeClaims, ServiceAccounts and Services referenced by pod templates and Ingresses that aren't defined in the validated files.")
+	flags.StringArrayVar(&opts.allowedReferences, "allow-reference", []string{}, "glob pattern of 'Kind/name' of the objects managed outside of the validated files (ie: 'Secret/registry-*'), whose references aren't checked by --check-references. Can be used several times")
+	flags.BoolVar(&opts.checkSelectors, "check-selectors", false, "report the Services whose selector doesn't match the pods of any Pod or workload in the validated files.")
+	flags.BoolVar(&opts.checkDuplicates, "check-duplicates", false, "report the resources defined more than once in the validated files (with the same API group, kind, namespace and name).")
+	flags.StringVar(&opts.defaultNamespace, "default-namespace", "default", "namespace of the resources without namespace, when checking the references, selectors and duplicates between them.")
+	flags.IntVarP(&opts.jobs, "jobs", "j", runtime.GOMAXPROCS(0), "number of files to validate concurrently.")
+	flags.StringVarP(&opts.outputFormat, "output", "o", report.FormatText, fmt.Sprintf("output format of the validation results. One of: %s", strings.Join(report.Formats, "|")))
+	flags.StringVar(&opts.configFile, "config", "", fmt.Sprintf("configuration file with the default options of the project (by default, the %s file found in the working directory or its parents). Flags take precedence over it", config.Filename))
 }
 
 // Execute executes the root command.
@@ -61,94 +125,497 @@ func Execute(version, date, commit string) error {
 
 func runValidate(opts validateOptions) (int, []validate.ValidationResult) {
 	totalResults := make([]validate.ValidationResult, 0)
-	fmt.Printf("Validating config in %s against schema in %s\n", utils.JoinNotEmptyStrings(", ", opts.filenames...), opts.openApiSchemaFilename)
+	reporter, err := report.NewReporter(opts.outputFormat, opts.strict, opts.stdout(), opts.stderr())
+	if err != nil {
+		fmt.Fprintln(opts.stderr(), err)
+		return 1, totalResults
+	}
+	exitCode, totalResults := validateWithReporter(opts, reporter)
+	err = reporter.Flush(report.Summarize(totalResults, exitCode))
+	if err != nil {
+		fmt.Fprintf(opts.stderr(), "Error writing results: %s\n", err)
+		exitCode = 1
+	}
+	return exitCode, totalResults
+}
+
+func validateWithReporter(opts validateOptions, reporter report.Reporter) (int, []validate.ValidationResult) {
+	totalResults := make([]validate.ValidationResult, 0)
+	schemaDescriptions := append([]string{}, opts.openApiSchemaFilenames...)
+	for _, kubernetesVersion := range opts.kubernetesVersions {
+		schemaDescriptions = append(schemaDescriptions, fmt.Sprintf("Kubernetes version %s", kubernetesVersion))
+	}
+	reporter.Logf("Validating config in %s against schema in %s\n", utils.JoinNotEmptyStrings(", ", opts.filenames...), utils.JoinNotEmptyStrings(", ", schemaDescriptions...))
 	exitCode := 0
+	if opts.configFile != "" {
+		reporter.Logf("Using configuration from %s\n", opts.configFile)
+	}
+	if len(opts.filenames) == 0 {
+		reporter.Logf("No files to validate, use -f, --filename or the 'files' of the configuration file\n")
+		return 1, totalResults
+	}
 
-	opeanApi2SpecsBytes, err := ioutil.ReadFile(opts.openApiSchemaFilename)
-	if err != nil {
-		fmt.Printf("Error loading specs from %s: %s\n", opts.filenames, err)
+	for _, pattern := range append(append(append([]string{}, opts.include...), opts.exclude...), opts.allowedReferences...) {
+		err := fs.ValidateGlob(pattern)
+		if err != nil {
+			reporter.Logf("%s\n", err)
+			return 1, totalResults
+		}
+	}
+
+	var knownFindings *baseline.Baseline
+	if opts.baseline != "" {
+		var err error
+		knownFindings, err = baseline.Load(opts.baseline)
+		if err != nil {
+			reporter.Logf("Error loading baseline: %s\n", err)
+			return 1, totalResults
+		}
+	}
+
+	validatorOptions := []validate.OpenApiValidatorOption{
+		validate.WithVerboseErrors(opts.verbose),
+		validate.WithDeprecationChecks(opts.checkDeprecations),
+		validate.WithRequiredNamespace(opts.requireNamespace),
+	}
+	schemaValidators := make([]schemaValidator, 0, len(opts.openApiSchemaFilenames)+len(opts.kubernetesVersions))
+	for _, schemaPath := range opts.openApiSchemaFilenames {
+		validators, err := loadSchemaValidators(reporter, schemaPath, validatorOptions...)
+		if err != nil {
+			reporter.Logf("Error loading specs from %s: %s\n", schemaPath, err)
+			return 1, totalResults
+		}
+		schemaValidators = append(schemaValidators, validators...)
+	}
+
+	schemaStore := store.New(opts.schemaStore)
+	for _, kubernetesVersion := range opts.kubernetesVersions {
+		storedVersion, err := schemaStore.Get(kubernetesVersion)
+		if err == nil && storedVersion.Schema == "" && storedVersion.Bundle == "" {
+			err = fmt.Errorf("No schema imported for Kubernetes version '%s'", storedVersion.Version)
+		}
+		if err != nil {
+			reporter.Logf("Error loading specs of Kubernetes version %s: %s\n", kubernetesVersion, err)
+			return 1, totalResults
+		}
+		// the compiled bundle already includes the CRDs of the version
+		schemaPath, crds := storedVersion.Bundle, []string{}
+		if schemaPath == "" {
+			schemaPath, crds = storedVersion.Schema, storedVersion.Crds
+		}
+		validator, err := newSchemaValidator(reporter, schemaPath, validatorOptions...)
+		if err != nil {
+			reporter.Logf("Error loading specs from %s: %s\n", schemaPath, err)
+			return 1, totalResults
+		}
+		versionValidators := []schemaValidator{{name: storedVersion.Version, validator: validator}}
+		err = addCrdSchemas(reporter, versionValidators, crds)
+		if err != nil {
+			return 1, totalResults
+		}
+		schemaValidators = append(schemaValidators, versionValidators...)
+	}
+
+	if len(schemaValidators) == 0 {
+		reporter.Logf("No schemas to validate against, use -s, --schema or --kubernetes-version\n")
 		return 1, totalResults
 	}
-	resourceValidator, err := validate.NewOpenApi2Validator(opeanApi2SpecsBytes)
+
+	err := addCrdSchemas(reporter, schemaValidators, opts.crds)
 	if err != nil {
-		fmt.Printf("Error loading specs from %s: %s\n", opts.openApiSchemaFilename, err)
 		return 1, totalResults
 	}
 
-	for _, crd := range opts.crds {
+	fileValidator := newMatrixFileValidator(schemaValidators, opts.overrides)
+	setValidators := newSetValidators(opts)
+
+	reporter.Logf("Results:\n")
+	sources := make([]string, 0)
+	for _, filename := range opts.filenames {
+		// case stdin:
+		if filename == "-" {
+			sources = append(sources, validate.StdinSource)
+			continue
+		}
+
+		walkOptions := fs.WalkOptions{Recursive: opts.recursive, Include: opts.include, Exclude: opts.exclude}
+		err := fs.ApplyToPathWithOptions(filename, walkOptions, func(file string) error {
+			sources = append(sources, file)
+			return nil
+		}, fs.IsManifestFilter)
+		if err != nil {
+			reporter.Logf("Error while validating %s: %s\n", filename, err)
+			exitCode = 1
+		}
+	}
+
+	hiddenFindings := 0
+	resources := make([]validate.LocatedResource, 0)
+	validateSources(sources, opts.jobs, func(source string) sourceResult {
+		if source == validate.StdinSource {
+			return validateSource(fileValidator, source, opts.input, len(setValidators) > 0)
+		}
+		file, err := os.Open(source)
+		if err != nil {
+			return sourceResult{err: err}
+		}
+		defer file.Close()
+		return validateSource(fileValidator, source, file, len(setValidators) > 0)
+	}, func(source string, result sourceResult) bool {
+		if result.err != nil && source == validate.StdinSource {
+			reporter.Logf("Error reading stdin: %s\n", result.err)
+			exitCode = 1
+			return false
+		}
+		if result.err != nil {
+			reporter.Logf("Error reading file %s: %s\n", source, result.err)
+			// continue processing other files in input
+			return true
+		}
+		if knownFindings != nil {
+			var hidden int
+			result.results, hidden = knownFindings.Filter(result.results)
+			hiddenFindings += hidden
+		}
+		reporter.Report(source, result.results)
+		totalResults = append(totalResults, result.results...)
+		resources = append(resources, result.resources...)
+		return true
+	})
+
+	if len(setValidators) > 0 {
+		setResults := make([]validate.ValidationResult, 0)
+		for _, setValidator := range setValidators {
+			setResults = append(setResults, setValidator.Validate(resources)...)
+		}
+		setResults = validate.ApplyOverrides(setResults, opts.overrides)
+		if knownFindings != nil {
+			var hidden int
+			setResults, hidden = knownFindings.Filter(setResults)
+			hiddenFindings += hidden
+		}
+		if len(setResults) == 0 {
+			reporter.Logf("No findings across files\n\n")
+		} else {
+			reporter.Logf("Results across files:\n")
+			reportBySource(reporter, sources, setResults)
+		}
+		totalResults = append(totalResults, setResults...)
+	}
+
+	if knownFindings != nil {
+		reporter.Logf("%d findings hidden by the baseline %s\n", hiddenFindings, opts.baseline)
+		if unmatched := knownFindings.Unmatched(); unmatched > 0 {
+			reporter.Logf("%d findings of the baseline are no longer found, update it with 'scheriff baseline create'\n", unmatched)
+		}
+	}
+
+	if containsSeverity(totalResults, opts.strict) {
+		exitCode = 1
+	}
+	return exitCode, totalResults
+}
+
+// addCrdSchemas adds the schemas of the CustomResourceDefinitions found in 'crds' to each of the validators
+func addCrdSchemas(reporter report.Reporter, schemaValidators []schemaValidator, crds []string) error {
+	for _, crd := range crds {
 		err := fs.ApplyToPathWithFilter(crd, false, func(file string) error {
-			fmt.Printf("Using CustomResourceDefinitions from %s\n", file)
-			fileBytes, err := ioutil.ReadFile(file)
+			reporter.Logf("Using CustomResourceDefinitions from %s\n", file)
+			crdFile, err := os.Open(file)
 			if err != nil {
 				return err
 			}
-			crdResources, err := kubernetes.ParseResourcesFromYaml(fileBytes)
+			defer crdFile.Close()
+			crdResources, err := kubernetes.ParseResourcesFromYaml(crdFile)
 			if err != nil {
 				return err
 			}
 			for _, crdResource := range crdResources {
-				err = resourceValidator.AddCrdSchemas(crdResource)
-				if err != nil {
-					return err
+				for _, schemaValidator := range schemaValidators {
+					err = schemaValidator.validator.AddCrdSchemas(crdResource)
+					if err != nil {
+						return err
+					}
 				}
 			}
 			return nil
 		}, fs.IsYamlFilter)
 		if err != nil {
-			fmt.Printf("Error loading CustomResourceDefinitions from %s: %s\n", crd, err)
+			reporter.Logf("Error loading CustomResourceDefinitions from %s: %s\n", crd, err)
 			// TODO: log warning instead?
-			return 1, totalResults
+			return err
 		}
 	}
+	return nil
+}
 
-	fileValidator := validate.NewYamlFileValidator(resourceValidator)
+// schemaValidator is a validator along with the name that identifies its schema in the results
+type schemaValidator struct {
+	name      string
+	validator *validate.OpenApiValidator
+}
 
-	fmt.Println("Results:")
-	for _, filename := range opts.filenames {
-		// case stdin:
-		if filename == "-" {
-			fileBytes, err := ioutil.ReadAll(opts.input)
-			if err != nil {
-				fmt.Printf("Error reading stdin: %s\n", err)
-				return 1, totalResults
+// loadSchemaValidators loads the schemas found in schemaPath. A directory of OpenAPI V2 files results in one
+// validator per file, named after the file (ie: a Kubernetes version), while any other path results in a single validator.
+func loadSchemaValidators(reporter report.Reporter, schemaPath string, options ...validate.OpenApiValidatorOption) ([]schemaValidator, error) {
+	fileInfo, err := os.Stat(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+	if fileInfo.IsDir() {
+		files, documents, err := readSchemaDirectory(schemaPath)
+		if err != nil {
+			return nil, err
+		}
+		openApi2Files := make([]string, 0, len(files))
+		for i, document := range documents {
+			if !validate.IsOpenApi3(document) {
+				openApi2Files = append(openApi2Files, files[i])
 			}
-			validationResults := fileValidator.Validate(fileBytes)
-			outputResult(validationResults)
-			totalResults = append(totalResults, validationResults...)
-			continue
 		}
-
-		err := fs.ApplyToPathWithFilter(filename, opts.recursive, func(file string) error {
-			fmt.Printf("Validating manifests in %s:\n", file)
-			fileBytes, err := ioutil.ReadFile(file)
-			if err != nil {
-				fmt.Printf("Error reading file %s: %s\n", file, err)
-				// continue processing other files in input
-				return nil
+		if len(openApi2Files) == len(files) && len(files) > 0 {
+			validators := make([]schemaValidator, 0, len(files))
+			for i, file := range files {
+				validator, err := validate.NewOpenApi2Validator(documents[i], options...)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %s", file, err)
+				}
+				name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+				validators = append(validators, schemaValidator{name: name, validator: validator})
 			}
+			return validators, nil
+		}
+		if len(openApi2Files) > 0 {
+			return nil, fmt.Errorf("%s mixes OpenAPI V3 documents with other files, such as %s", schemaPath, openApi2Files[0])
+		}
+		validator, err := newOpenApi3DirectoryValidator(schemaPath, files, documents, options...)
+		if err != nil {
+			return nil, err
+		}
+		return []schemaValidator{{name: schemaPath, validator: validator}}, nil
+	}
+	validator, err := newSchemaValidator(reporter, schemaPath, options...)
+	if err != nil {
+		return nil, err
+	}
+	return []schemaValidator{{name: schemaPath, validator: validator}}, nil
+}
 
-			validationResults := fileValidator.Validate(fileBytes)
-			outputResult(validationResults)
-			totalResults = append(totalResults, validationResults...)
-			return nil
+// readSchemaDirectory reads the JSON files of a schema directory, returning their names and contents
+func readSchemaDirectory(dir string) ([]string, [][]byte, error) {
+	files := make([]string, 0)
+	documents := make([][]byte, 0)
+	err := fs.ApplyToPathWithFilter(dir, true, func(file string) error {
+		fileBytes, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		documents = append(documents, fileBytes)
+		return nil
+	}, fs.IsJsonFilter)
+	return files, documents, err
+}
 
-		}, fs.IsYamlFilter)
+// newOpenApi3DirectoryValidator builds a validator from the OpenAPI V3 documents read from a directory (ie: one per group-version)
+func newOpenApi3DirectoryValidator(dir string, files []string, documents [][]byte, options ...validate.OpenApiValidatorOption) (*validate.OpenApiValidator, error) {
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("No OpenAPI V3 documents found in %s", dir)
+	}
+	for i, document := range documents {
+		if !validate.IsOpenApi3(document) {
+			return nil, fmt.Errorf("%s is not an OpenAPI V3 document", files[i])
+		}
+	}
+	return validate.NewOpenApi3Validator(documents, options...)
+}
+
+// newSchemaValidator loads the schemas to validate against from either an OpenAPI V2 file, an OpenAPI V3 document,
+// a directory containing OpenAPI V3 documents (ie: one per group-version) or a schema bundle compiled with 'scheriff schema compile'
+func newSchemaValidator(reporter report.Reporter, schemaPath string, options ...validate.OpenApiValidatorOption) (*validate.OpenApiValidator, error) {
+	fileInfo, err := os.Stat(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if fileInfo.IsDir() {
+		files, documents, err := readSchemaDirectory(schemaPath)
 		if err != nil {
-			fmt.Printf("Error while validating %s: %s\n", filename, err)
-			exitCode = 1
+			return nil, err
 		}
+		return newOpenApi3DirectoryValidator(schemaPath, files, documents, options...)
 	}
-	if containsSeverity(totalResults, opts.strict) {
-		exitCode = 1
+
+	specsBytes, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		return nil, err
 	}
-	return exitCode, totalResults
+	if validate.IsSchemaBundle(specsBytes) {
+		bundle, err := validate.ReadSchemaBundle(specsBytes)
+		if err != nil {
+			return nil, err
+		}
+		err = checkBundleChecksum(reporter, schemaPath, bundle)
+		if err != nil {
+			return nil, err
+		}
+		return validate.NewBundleValidator(bundle, options...)
+	}
+	if validate.IsOpenApi3(specsBytes) {
+		return validate.NewOpenApi3Validator([][]byte{specsBytes}, options...)
+	}
+	return validate.NewOpenApi2Validator(specsBytes, options...)
 }
 
-func outputResult(results []validate.ValidationResult) {
+// matrixFileValidator validates files against several schemas, setting the schema name in each of the results
+type matrixFileValidator struct {
+	names          []string
+	fileValidators []validate.FileValidator
+}
+
+func newMatrixFileValidator(schemaValidators []schemaValidator, overrides []validate.RuleOverride) validate.FileValidator {
+	if len(schemaValidators) == 1 {
+		return validate.NewYamlFileValidator(newResourceValidator(schemaValidators[0].validator, overrides), schemaValidators[0].validator.HasKind)
+	}
+	matrixValidator := &matrixFileValidator{}
+	for _, schemaValidator := range schemaValidators {
+		matrixValidator.names = append(matrixValidator.names, schemaValidator.name)
+		matrixValidator.fileValidators = append(matrixValidator.fileValidators, validate.NewYamlFileValidator(newResourceValidator(schemaValidator.validator, overrides), schemaValidator.validator.HasKind))
+	}
+	return matrixValidator
+}
+
+// newResourceValidator chains the semantic validations that aren't part of the schemas after the schema validation,
+// and applies the overrides to the findings of all of them
+func newResourceValidator(openApiValidator *validate.OpenApiValidator, overrides []validate.RuleOverride) validate.ResourceValidator {
+	return validate.NewOverridesValidator(validate.NewChainValidator(openApiValidator, validate.NewMetadataValidator(), validate.NewSelectorsValidator()), overrides)
+}
+
+// Validate reads all the input, as it's validated once for each schema
+func (matrixValidator *matrixFileValidator) Validate(reader io.Reader) ([]validate.ValidationResult, error) {
+	results, _, err := matrixValidator.validate("", reader, false)
+	return results, err
+}
+
+// ValidateResources validates the input against each schema as Validate does, returning the resources read from the first one
+func (matrixValidator *matrixFileValidator) ValidateResources(source string, reader io.Reader) ([]validate.ValidationResult, []validate.LocatedResource, error) {
+	return matrixValidator.validate(source, reader, true)
+}
+
+func (matrixValidator *matrixFileValidator) validate(source string, reader io.Reader, collect bool) ([]validate.ValidationResult, []validate.LocatedResource, error) {
+	fileBytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	results := make([]validate.ValidationResult, 0)
+	var resources []validate.LocatedResource
+	for i, fileValidator := range matrixValidator.fileValidators {
+		var schemaResults []validate.ValidationResult
+		if collect && i == 0 {
+			schemaResults, resources, err = fileValidator.ValidateResources(source, bytes.NewReader(fileBytes))
+		} else {
+			schemaResults, err = fileValidator.Validate(bytes.NewReader(fileBytes))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for j := range schemaResults {
+			schemaResults[j].Schema = matrixValidator.names[i]
+		}
+		results = append(results, schemaResults...)
+	}
+	return results, resources, nil
+}
+
+// sourceResult holds the results of validating a source, or the error found when reading it
+type sourceResult struct {
+	results []validate.ValidationResult
+	// resources are the resources read from the source, only when they are validated as a set too
+	resources []validate.LocatedResource
+	err       error
+}
+
+// validateSources validates the sources with a pool of 'jobs' workers, and calls 'reportFunc' with the result of each source
+// in the same order as the sources, as soon as it's available. When 'reportFunc' returns false, the remaining results are discarded.
+func validateSources(sources []string, jobs int, validateFunc func(source string) sourceResult, reportFunc func(source string, result sourceResult) bool) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	results := make([]chan sourceResult, len(sources))
+	for i := range results {
+		results[i] = make(chan sourceResult, 1)
+	}
+	indexes := make(chan int)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(indexes)
+		for i := range sources {
+			select {
+			case indexes <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+	for worker := 0; worker < jobs; worker++ {
+		go func() {
+			for i := range indexes {
+				results[i] <- validateFunc(sources[i])
+			}
+		}()
+	}
+
+	for i, source := range sources {
+		if !reportFunc(source, <-results[i]) {
+			return
+		}
+	}
+}
+
+// validateSource validates the resources of a source, and returns them for the validations across resources when 'collect' is set
+func validateSource(fileValidator validate.FileValidator, source string, reader io.Reader, collect bool) sourceResult {
+	var validationResults []validate.ValidationResult
+	var resources []validate.LocatedResource
+	var err error
+	if collect {
+		validationResults, resources, err = fileValidator.ValidateResources(source, reader)
+	} else {
+		validationResults, err = fileValidator.Validate(reader)
+	}
+	for i := range validationResults {
+		validationResults[i].Source = source
+	}
+	return sourceResult{results: validationResults, resources: resources, err: err}
+}
+
+// newSetValidators returns the enabled validations across the resources of all the sources
+func newSetValidators(opts validateOptions) []validate.SetValidator {
+	setValidators := make([]validate.SetValidator, 0)
+	if opts.checkReferences {
+		setValidators = append(setValidators, validate.NewReferencesValidator(opts.allowedReferences, opts.defaultNamespace))
+	}
+	if opts.checkSelectors {
+		setValidators = append(setValidators, validate.NewServiceSelectorsValidator(opts.defaultNamespace))
+	}
+	if opts.checkDuplicates {
+		setValidators = append(setValidators, validate.NewDuplicatesValidator(opts.defaultNamespace))
+	}
+	return setValidators
+}
+
+// reportBySource reports the results of the validations across resources grouped by their source, in the order of the sources
+func reportBySource(reporter report.Reporter, sources []string, results []validate.ValidationResult) {
+	bySource := make(map[string][]validate.ValidationResult)
 	for _, result := range results {
-		fmt.Printf("\t - %s, %s (%s): %s\n", colorSeverity(result.Severity), utils.JoinNotEmptyStrings("/", result.Namespace, result.Name), result.Kind, result.Message)
+		bySource[result.Source] = append(bySource[result.Source], result)
+	}
+	for _, source := range sources {
+		if sourceResults, ok := bySource[source]; ok {
+			reporter.Report(source, sourceResults)
+			delete(bySource, source)
+		}
 	}
-	fmt.Println()
 }
 
 func containsSeverity(results []validate.ValidationResult, strict bool) bool {
@@ -164,19 +631,3 @@ func containsSeverity(results []validate.ValidationResult, strict bool) bool {
 	}
 	return false
 }
-
-func colorSeverity(severity validate.Severity) string {
-	red := color.FgRed.Render
-	green := color.FgGreen.Render
-	yellow := color.FgYellow.Render
-	switch severity {
-	case validate.SeverityError:
-		return red(severity)
-	case validate.SeverityWarning:
-		return yellow(severity)
-	case validate.SeverityOK:
-		return green(severity)
-	default:
-		return (string)(severity)
-	}
-}
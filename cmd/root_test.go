@@ -1,15 +1,26 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/fllaca/scheriff/pkg/report"
+	"github.com/fllaca/scheriff/pkg/store"
 	"github.com/fllaca/scheriff/pkg/validate"
 	"github.com/stretchr/testify/assert"
 )
 
+func intPointer(value int) *int {
+	return &value
+}
+
 type errorReader struct{}
 
 func (errorReader errorReader) Read(p []byte) (int, error) {
@@ -37,201 +48,201 @@ func TestValidate(t *testing.T) {
 		{
 			name: "test valid deploy",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/deployment_valid.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/deployment_valid.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 0,
 			expectedResults: []validate.ValidationResult{
-				{"valid", validate.SeverityOK, "some-app-envoy", "example", "v1/Service"},
-				{"valid", validate.SeverityOK, "some-app-envoy", "example", "extensions/v1beta1/Deployment"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "some-app-envoy", Namespace: "example", Kind: "v1/Service", Source: "testdata/manifests/deployment_valid.yaml", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "some-app-envoy", Namespace: "example", Kind: "extensions/v1beta1/Deployment", Source: "testdata/manifests/deployment_valid.yaml", Document: 1},
 			},
 		},
 		{
 			name: "test invalid deployment",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/deployment_invalid.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/deployment_invalid.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults: []validate.ValidationResult{
-				{"valid", "OK", "some-app-envoy", "example", "v1/Service"},
-				{"Error at \"/spec/template/spec/containers/0/name\":Property 'name' is missing", "ERROR", "some-app-envoy", "example", "extensions/v1beta1/Deployment"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "some-app-envoy", Namespace: "example", Kind: "v1/Service", Source: "testdata/manifests/deployment_invalid.yaml", Document: 0},
+				{Message: "Error at \"/spec/template/spec/containers/0/name\":Property 'name' is missing", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "some-app-envoy", Namespace: "example", Kind: "extensions/v1beta1/Deployment", Source: "testdata/manifests/deployment_invalid.yaml", Document: 1, Path: "/spec/template/spec/containers/0/name", Line: 49, Column: 9, Constraint: "required"},
 			},
 		},
 		{
 			name: "test invalid yaml",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/invalid_yaml.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/invalid_yaml.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults: []validate.ValidationResult{
-				{"Error parsing k8s resource from document 0: error converting YAML to JSON: yaml: line 3: mapping values are not allowed in this context\n", validate.SeverityError, "", "", ""},
+				{Message: "Error parsing k8s resource from document 0: yaml: line 3: mapping values are not allowed in this context\n", Severity: validate.SeverityError, Rule: validate.RuleParseError, Name: "", Namespace: "", Kind: "", Source: "testdata/manifests/invalid_yaml.yaml", Document: 0, Line: 3},
 			},
 		},
 		{
 			name: "test folder recursive",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/test_recursive"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/cert-manager-legacy-v0.15.0.crds.yaml"},
-				recursive:             true,
+				filenames:              []string{"testdata/manifests/test_recursive"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/cert-manager-legacy-v0.15.0.crds.yaml"},
+				recursive:              true,
 			},
 			expectedExitCode: 0,
 			expectedResults: []validate.ValidationResult{
-				{"valid", validate.SeverityOK, "example-cert", "example", "cert-manager.io/v1alpha2/Certificate"},
-				{"valid", validate.SeverityOK, "some-app-envoy", "example", "v1/Service"},
-				{"Kind 'example.io/v1/UnknownCRD' not found in schema", validate.SeverityWarning, "example-unknown-kind", "example", "example.io/v1/UnknownCRD"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "example-cert", Namespace: "example", Kind: "cert-manager.io/v1alpha2/Certificate", Source: "testdata/manifests/test_recursive/certificate_valid.yaml", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "some-app-envoy", Namespace: "example", Kind: "v1/Service", Source: "testdata/manifests/test_recursive/deployment_valid.yaml", Document: 0},
+				{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "example-unknown-kind", Namespace: "example", Kind: "example.io/v1/UnknownCRD", Source: "testdata/manifests/test_recursive/nested_dir/unknown_kind.yaml", Document: 0, Line: 1, Column: 1},
 			},
 		},
 		{
 			name: "test folder not recursive",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/test_recursive"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/cert-manager-legacy-v0.15.0.crds.yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/test_recursive"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/cert-manager-legacy-v0.15.0.crds.yaml"},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 0,
 			expectedResults: []validate.ValidationResult{
-				{"valid", validate.SeverityOK, "example-cert", "example", "cert-manager.io/v1alpha2/Certificate"},
-				{"valid", validate.SeverityOK, "some-app-envoy", "example", "v1/Service"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "example-cert", Namespace: "example", Kind: "cert-manager.io/v1alpha2/Certificate", Source: "testdata/manifests/test_recursive/certificate_valid.yaml", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "some-app-envoy", Namespace: "example", Kind: "v1/Service", Source: "testdata/manifests/test_recursive/deployment_valid.yaml", Document: 0},
 			},
 		},
 		{
 			name: "test valid crd",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/certificate_valid.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/cert-manager-legacy-v0.15.0.crds.yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/certificate_valid.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/cert-manager-legacy-v0.15.0.crds.yaml"},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 0,
 			expectedResults: []validate.ValidationResult{
-				{"valid", validate.SeverityOK, "example-cert", "example", "cert-manager.io/v1alpha2/Certificate"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "example-cert", Namespace: "example", Kind: "cert-manager.io/v1alpha2/Certificate", Source: "testdata/manifests/certificate_valid.yaml", Document: 0},
 			},
 		},
 		{
 			name: "test invalid crd",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/certificate_invalid.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/cert-manager-legacy-v0.15.0.crds.yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/certificate_invalid.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/cert-manager-legacy-v0.15.0.crds.yaml"},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults: []validate.ValidationResult{
-				{"Error at \"/spec/secretName\":Property 'secretName' is missing", validate.SeverityError, "example-invalid-cert", "example", "cert-manager.io/v1alpha2/Certificate"},
+				{Message: "Error at \"/spec/secretName\":Property 'secretName' is missing", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "example-invalid-cert", Namespace: "example", Kind: "cert-manager.io/v1alpha2/Certificate", Source: "testdata/manifests/certificate_invalid.yaml", Document: 0, Path: "/spec/secretName", Line: 6, Column: 1, Constraint: "required"},
 			},
 		},
 		{
 			name: "test unknown crd",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/unknown_kind.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/unknown_kind.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 0,
 			expectedResults: []validate.ValidationResult{
-				{"Kind 'example.io/v1/UnknownCRD' not found in schema", validate.SeverityWarning, "example-unknown-kind", "example", "example.io/v1/UnknownCRD"},
+				{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "example-unknown-kind", Namespace: "example", Kind: "example.io/v1/UnknownCRD", Source: "testdata/manifests/unknown_kind.yaml", Document: 0, Line: 1, Column: 1},
 			},
 		},
 		{
 			name: "test unknown crd strict",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/unknown_kind.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{},
-				recursive:             false,
-				strict:                true,
+				filenames:              []string{"testdata/manifests/unknown_kind.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
+				strict:                 true,
 			},
 			expectedExitCode: 1,
 			expectedResults: []validate.ValidationResult{
-				{"Kind 'example.io/v1/UnknownCRD' not found in schema", validate.SeverityWarning, "example-unknown-kind", "example", "example.io/v1/UnknownCRD"},
+				{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "example-unknown-kind", Namespace: "example", Kind: "example.io/v1/UnknownCRD", Source: "testdata/manifests/unknown_kind.yaml", Document: 0, Line: 1, Column: 1},
 			},
 		},
 		{
 			name: "test unknown crd then invalid yaml",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/unknown_kind.yaml", "testdata/manifests/invalid_yaml.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/unknown_kind.yaml", "testdata/manifests/invalid_yaml.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults: []validate.ValidationResult{
-				{"Kind 'example.io/v1/UnknownCRD' not found in schema", validate.SeverityWarning, "example-unknown-kind", "example", "example.io/v1/UnknownCRD"},
-				{"Error parsing k8s resource from document 0: error converting YAML to JSON: yaml: line 3: mapping values are not allowed in this context\n", validate.SeverityError, "", "", ""},
+				{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "example-unknown-kind", Namespace: "example", Kind: "example.io/v1/UnknownCRD", Source: "testdata/manifests/unknown_kind.yaml", Document: 0, Line: 1, Column: 1},
+				{Message: "Error parsing k8s resource from document 0: yaml: line 3: mapping values are not allowed in this context\n", Severity: validate.SeverityError, Rule: validate.RuleParseError, Name: "", Namespace: "", Kind: "", Source: "testdata/manifests/invalid_yaml.yaml", Document: 0, Line: 3},
 			},
 		},
 		{
 			name: "test unknown crd then invalid yaml strict",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/unknown_kind.yaml", "testdata/manifests/invalid_yaml.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{},
-				recursive:             false,
-				strict:                true,
+				filenames:              []string{"testdata/manifests/unknown_kind.yaml", "testdata/manifests/invalid_yaml.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
+				strict:                 true,
 			},
 			expectedExitCode: 1,
 			expectedResults: []validate.ValidationResult{
-				{"Kind 'example.io/v1/UnknownCRD' not found in schema", validate.SeverityWarning, "example-unknown-kind", "example", "example.io/v1/UnknownCRD"},
-				{"Error parsing k8s resource from document 0: error converting YAML to JSON: yaml: line 3: mapping values are not allowed in this context\n", validate.SeverityError, "", "", ""},
+				{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "example-unknown-kind", Namespace: "example", Kind: "example.io/v1/UnknownCRD", Source: "testdata/manifests/unknown_kind.yaml", Document: 0, Line: 1, Column: 1},
+				{Message: "Error parsing k8s resource from document 0: yaml: line 3: mapping values are not allowed in this context\n", Severity: validate.SeverityError, Rule: validate.RuleParseError, Name: "", Namespace: "", Kind: "", Source: "testdata/manifests/invalid_yaml.yaml", Document: 0, Line: 3},
 			},
 		},
 		{
 			name: "test unknown crd then invalid yaml in same file",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/warn_error.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/warn_error.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults: []validate.ValidationResult{
-				{"Kind 'example.io/v1/UnknownCRD' not found in schema", validate.SeverityWarning, "example-unknown-kind", "example", "example.io/v1/UnknownCRD"},
-				{"Error parsing k8s resource from document 1: error converting YAML to JSON: yaml: line 2: mapping values are not allowed in this context\n", validate.SeverityError, "", "", ""},
+				{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "example-unknown-kind", Namespace: "example", Kind: "example.io/v1/UnknownCRD", Source: "testdata/manifests/warn_error.yaml", Document: 0, Line: 1, Column: 1},
+				{Message: "Error parsing k8s resource from document 1: yaml: line 10: mapping values are not allowed in this context\n", Severity: validate.SeverityError, Rule: validate.RuleParseError, Name: "", Namespace: "", Kind: "", Source: "testdata/manifests/warn_error.yaml", Document: 1, Line: 10},
 			},
 		},
 		{
 			name: "test unknown crd then invalid yaml in same file strict",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/warn_error.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{},
-				recursive:             false,
-				strict:                true,
+				filenames:              []string{"testdata/manifests/warn_error.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
+				strict:                 true,
 			},
 			expectedExitCode: 1,
 			expectedResults: []validate.ValidationResult{
-				{"Kind 'example.io/v1/UnknownCRD' not found in schema", validate.SeverityWarning, "example-unknown-kind", "example", "example.io/v1/UnknownCRD"},
-				{"Error parsing k8s resource from document 1: error converting YAML to JSON: yaml: line 2: mapping values are not allowed in this context\n", validate.SeverityError, "", "", ""},
+				{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "example-unknown-kind", Namespace: "example", Kind: "example.io/v1/UnknownCRD", Source: "testdata/manifests/warn_error.yaml", Document: 0, Line: 1, Column: 1},
+				{Message: "Error parsing k8s resource from document 1: yaml: line 10: mapping values are not allowed in this context\n", Severity: validate.SeverityError, Rule: validate.RuleParseError, Name: "", Namespace: "", Kind: "", Source: "testdata/manifests/warn_error.yaml", Document: 1, Line: 10},
 			},
 		},
 		{
 			name: "test schema with invalid json",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests"},
-				openApiSchemaFilename: "testdata/schemas/invalid-json.json",
-				crds:                  []string{},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests"},
+				openApiSchemaFilenames: []string{"testdata/schemas/invalid-json.json"},
+				crds:                   []string{},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults:  []validate.ValidationResult{},
@@ -239,53 +250,65 @@ func TestValidate(t *testing.T) {
 		{
 			name: "test valid crd v1",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/crd_v1_crontab.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/v1_crontab.yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/crd_v1_crontab.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/v1_crontab.yaml"},
+				recursive:              false,
+				strict:                 false,
+			},
+			expectedExitCode: 0,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "my-new-cron-object", Namespace: "", Kind: "stable.example.com/v1/CronTab", Source: "testdata/manifests/crd_v1_crontab.yaml", Document: 0},
+			},
+		},
+		{
+			name: "test crd whose kind ends in List",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/crd_v1_ipallowlist.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/v1_ipallowlist.yaml"},
 			},
 			expectedExitCode: 0,
 			expectedResults: []validate.ValidationResult{
-				{"valid", validate.SeverityOK, "my-new-cron-object", "", "stable.example.com/v1/CronTab"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "office", Kind: "network.example.com/v1/IPAllowList", Source: "testdata/manifests/crd_v1_ipallowlist.yaml", Document: 0},
 			},
 		},
 		{
 			name: "test crd v1beta1 without schemas",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/crd_v1_crontab.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/without_schemas.yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/crd_v1_crontab.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/without_schemas.yaml"},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 0,
 			expectedResults: []validate.ValidationResult{
-				{"valid", validate.SeverityOK, "my-new-cron-object", "", "stable.example.com/v1/CronTab"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "my-new-cron-object", Namespace: "", Kind: "stable.example.com/v1/CronTab", Source: "testdata/manifests/crd_v1_crontab.yaml", Document: 0},
 			},
 		},
 		{
 			name: "test crd v1beta1 without default 'spec.validation.schema'",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/crd_v1_crontab.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/crontab_without_default_val.yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/crd_v1_crontab.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/crontab_without_default_val.yaml"},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 0,
 			expectedResults: []validate.ValidationResult{
-				{"valid", validate.SeverityOK, "my-new-cron-object", "", "stable.example.com/v1/CronTab"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "my-new-cron-object", Namespace: "", Kind: "stable.example.com/v1/CronTab", Source: "testdata/manifests/crd_v1_crontab.yaml", Document: 0},
 			},
 		},
 		{
 			name: "test unknown Kind in crd definition",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/crd_v1_crontab.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/unknown_crd_kind.yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/crd_v1_crontab.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/unknown_crd_kind.yaml"},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults:  []validate.ValidationResult{},
@@ -293,11 +316,11 @@ func TestValidate(t *testing.T) {
 		{
 			name: "test invalid crd definition",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/crd_v1_crontab.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/invalid_crd.yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/crd_v1_crontab.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/invalid_crd.yaml"},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults:  []validate.ValidationResult{},
@@ -305,11 +328,11 @@ func TestValidate(t *testing.T) {
 		{
 			name: "test crd with bad yaml syntax",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/crd_v1_crontab.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/invalid_yaml.yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/crd_v1_crontab.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/invalid_yaml.yaml"},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults:  []validate.ValidationResult{},
@@ -317,11 +340,11 @@ func TestValidate(t *testing.T) {
 		{
 			name: "test non-existing manifests folder",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/doesnotexist"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/invalid_crd.yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/doesnotexist"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/invalid_crd.yaml"},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults:  []validate.ValidationResult{},
@@ -329,11 +352,11 @@ func TestValidate(t *testing.T) {
 		{
 			name: "test non-existing schema file",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/crd_v1_crontab.yaml"},
-				openApiSchemaFilename: "testdata/schemas/doesnotexist.json",
-				crds:                  []string{"testdata/crds/invalid_crd.yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/crd_v1_crontab.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/doesnotexist.json"},
+				crds:                   []string{"testdata/crds/invalid_crd.yaml"},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults:  []validate.ValidationResult{},
@@ -341,11 +364,11 @@ func TestValidate(t *testing.T) {
 		{
 			name: "test non-existing crd file",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/crd_v1_crontab.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/doesnotexist.yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/crd_v1_crontab.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/doesnotexist.yaml"},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults:  []validate.ValidationResult{},
@@ -353,11 +376,11 @@ func TestValidate(t *testing.T) {
 		{
 			name: "test crd invalid yaml",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/crd_v1_crontab.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/invalid_yaml"},
-				recursive:             false,
-				strict:                false,
+				filenames:              []string{"testdata/manifests/crd_v1_crontab.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/invalid_yaml"},
+				recursive:              false,
+				strict:                 false,
 			},
 			expectedExitCode: 1,
 			expectedResults:  []validate.ValidationResult{},
@@ -365,70 +388,416 @@ func TestValidate(t *testing.T) {
 		{
 			name: "test additional properties",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/deployment_additional_properties.yaml", "testdata/manifests/cm_managed_fields.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{},
-				recursive:             false,
+				filenames:              []string{"testdata/manifests/deployment_additional_properties.yaml", "testdata/manifests/cm_managed_fields.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "test", Namespace: "default", Kind: "v1/ConfigMap", Source: "testdata/manifests/deployment_additional_properties.yaml", Document: 0},
+				{Message: "Error at \"/spec/template/spec\":Property 'unexpectedAdditionalProperty' is unsupported", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "some-app-envoy", Namespace: "example", Kind: "apps/v1/Deployment", Source: "testdata/manifests/deployment_additional_properties.yaml", Document: 1, Path: "/spec/template/spec", Line: 27, Column: 5, Constraint: "properties"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "test-cm", Namespace: "default", Kind: "v1/ConfigMap", Source: "testdata/manifests/cm_managed_fields.yaml", Document: 0},
+			},
+		},
+		{
+			name: "test multiple errors in the same resource",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/deployment_multiple_errors.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
 			},
 			expectedExitCode: 1,
 			expectedResults: []validate.ValidationResult{
-				{Message: "valid", Severity: "OK", Name: "test", Namespace: "default", Kind: "v1/ConfigMap"},
-				{Message: "Error at \"/spec/template/spec\":Property 'unexpectedAdditionalProperty' is unsupported", Severity: "ERROR", Name: "some-app-envoy", Namespace: "example", Kind: "apps/v1/Deployment"},
-				{Message: "valid", Severity: "OK", Name: "test-cm", Namespace: "default", Kind: "v1/ConfigMap"},
+				{Message: "Error at \"/spec/replicas\":Field must be set to integer or not be present", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "some-app-envoy", Namespace: "example", Kind: "apps/v1/Deployment", Source: "testdata/manifests/deployment_multiple_errors.yaml", Document: 0, Path: "/spec/replicas", Line: 7, Column: 3, Constraint: "type", Expected: "integer", Actual: "\"two\""},
+				{Message: "Error at \"/spec/template/spec/containers/0/name\":Property 'name' is missing", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "some-app-envoy", Namespace: "example", Kind: "apps/v1/Deployment", Source: "testdata/manifests/deployment_multiple_errors.yaml", Document: 0, Path: "/spec/template/spec/containers/0/name", Line: 20, Column: 9, Constraint: "required"},
+				{Message: "Error at \"/spec/template/spec/containers/1/ports/0/containerPort\":Field must be set to integer or not be present", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "some-app-envoy", Namespace: "example", Kind: "apps/v1/Deployment", Source: "testdata/manifests/deployment_multiple_errors.yaml", Document: 0, Path: "/spec/template/spec/containers/1/ports/0/containerPort", Line: 24, Column: 11, Constraint: "type", Expected: "integer", Actual: "\"http\""},
+				{Message: "Error at \"/spec\":Property 'unknownField' is unsupported", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "some-app-envoy", Namespace: "example", Kind: "apps/v1/Deployment", Source: "testdata/manifests/deployment_multiple_errors.yaml", Document: 0, Path: "/spec", Line: 6, Column: 1, Constraint: "properties"},
+			},
+		},
+		{
+			name: "test openapi v3 schemas directory",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/v3_resources.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/openapi-v3"},
+				crds:                   []string{},
+				recursive:              false,
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "some-app", Namespace: "example", Kind: "v1/Service", Source: "testdata/manifests/v3_resources.yaml", Document: 0},
+				{Message: "Error at \"/spec/template/spec/containers/0/name\":Property 'name' is missing", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "some-app", Namespace: "example", Kind: "apps/v1/Deployment", Source: "testdata/manifests/v3_resources.yaml", Document: 1, Path: "/spec/template/spec/containers/0/name", Line: 34, Column: 9, Constraint: "required"},
+				{Message: "Error at \"/data/unexpected\":Field must be set to string or not be present", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "some-app", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/v3_resources.yaml", Document: 2, Path: "/data/unexpected", Line: 43, Column: 3, Constraint: "type", Expected: "string", Actual: "1"},
+			},
+		},
+		{
+			name: "test openapi v3 single document",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/v3_resources.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/openapi-v3/apis/apps/v1.json"},
+				crds:                   []string{},
+				recursive:              false,
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "Kind 'v1/Service' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "some-app", Namespace: "example", Kind: "v1/Service", Source: "testdata/manifests/v3_resources.yaml", Document: 0, Line: 1, Column: 1},
+				{Message: "Error at \"/spec/template/spec/containers/0/name\":Property 'name' is missing", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "some-app", Namespace: "example", Kind: "apps/v1/Deployment", Source: "testdata/manifests/v3_resources.yaml", Document: 1, Path: "/spec/template/spec/containers/0/name", Line: 34, Column: 9, Constraint: "required"},
+				{Message: "Kind 'v1/ConfigMap' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "some-app", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/v3_resources.yaml", Document: 2, Line: 36, Column: 1},
+			},
+		},
+		{
+			name: "test schemas directory with non openapi v3 documents",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/v3_resources.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas"},
+				crds:                   []string{},
+				recursive:              false,
 			},
+			expectedExitCode: 1,
+			expectedResults:  []validate.ValidationResult{},
 		},
 		{
 			name: "test nullable properties",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/ns_nullable_field.yaml"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{},
-				recursive:             false,
+				filenames:              []string{"testdata/manifests/ns_nullable_field.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
 			},
 			expectedExitCode: 0,
 			expectedResults: []validate.ValidationResult{
-				{Message: "valid", Severity: "OK", Name: "test", Namespace: "", Kind: "v1/Namespace"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "test", Namespace: "", Kind: "v1/Namespace", Source: "testdata/manifests/ns_nullable_field.yaml", Document: 0},
 			},
 		},
 		{
 			name: "test validating input files without yaml/yml extension",
 			opts: validateOptions{
-				filenames:             []string{"testdata/manifests/non_yaml_extension.txt"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{},
-				recursive:             false,
+				filenames:              []string{"testdata/manifests/non_yaml_extension.txt"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{},
+				recursive:              false,
 			},
 			expectedExitCode: 0,
 			expectedResults: []validate.ValidationResult{
-				{Message: "valid", Severity: "OK", Name: "test-cm", Namespace: "default", Kind: "v1/ConfigMap"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "test-cm", Namespace: "default", Kind: "v1/ConfigMap", Source: "testdata/manifests/non_yaml_extension.txt", Document: 0},
 			},
 		},
 		{
 			name: "test stdin",
 			opts: validateOptions{
-				filenames:             []string{"-"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/crontab_without_default_val.yaml"},
-				input:                 openFile(t, "testdata/manifests/crd_v1_crontab.yaml"),
-				recursive:             false,
+				filenames:              []string{"-"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/crontab_without_default_val.yaml"},
+				input:                  openFile(t, "testdata/manifests/crd_v1_crontab.yaml"),
+				recursive:              false,
+			},
+			expectedExitCode: 0,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "my-new-cron-object", Namespace: "", Kind: "stable.example.com/v1/CronTab", Source: "stdin", Document: 0},
+			},
+		},
+		{
+			name: "test JSON manifests",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/test_json"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+				recursive:              false,
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "other", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/test_json/configmap.json", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/test_json/configmaps.json", Document: 0},
+				{Message: "Error at \"/data/replicas\":Field must be set to string or not be present", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "invalid", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/test_json/configmaps.json", Document: 1, Path: "/data/replicas", Line: 12, Column: 14, Constraint: "type", Expected: "string", Actual: "1"},
+			},
+		},
+		{
+			name: "test stdin JSON lines",
+			opts: validateOptions{
+				filenames:              []string{"-"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+				input:                  openFile(t, "testdata/manifests/configmaps.jsonl"),
+				recursive:              false,
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "first", Namespace: "example", Kind: "v1/ConfigMap", Source: "stdin", Document: 0},
+				{Message: "Error at \"/data/replicas\":Field must be set to string or not be present", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "second", Namespace: "example", Kind: "v1/ConfigMap", Source: "stdin", Document: 1, Path: "/data/replicas", Line: 2, Column: 108, Constraint: "type", Expected: "string", Actual: "2"},
+			},
+		},
+		{
+			name: "test ignore files",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/test_ignore"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+				recursive:              true,
+				exclude:                []string{"**/Chart.yaml"},
+			},
+			expectedExitCode: 0,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "app", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/test_ignore/app/configmap.yaml", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "values", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/test_ignore/app/values-resource.yaml", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "root", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/test_ignore/configmap.yaml", Document: 0},
+			},
+		},
+		{
+			name: "test include patterns",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/test_ignore"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+				recursive:              true,
+				include:                []string{"app/**/*map.yaml", "testdata/manifests/test_ignore/app/values-*.yaml"},
 			},
 			expectedExitCode: 0,
 			expectedResults: []validate.ValidationResult{
-				{Message: "valid", Severity: validate.SeverityOK, Name: "my-new-cron-object", Namespace: "", Kind: "stable.example.com/v1/CronTab"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "app", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/test_ignore/app/configmap.yaml", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "values", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/test_ignore/app/values-resource.yaml", Document: 0},
 			},
 		},
+		{
+			name: "test invalid glob pattern",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/test_ignore"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+				exclude:                []string{"[app"},
+			},
+			expectedExitCode: 1,
+			expectedResults:  []validate.ValidationResult{},
+		},
 		{
 			name: "test stdin error",
 			opts: validateOptions{
-				filenames:             []string{"-"},
-				openApiSchemaFilename: "testdata/schemas/k8s-1.17.0.json",
-				crds:                  []string{"testdata/crds/crontab_without_default_val.yaml"},
-				input:                 errorReader{},
-				recursive:             false,
+				filenames:              []string{"-"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				crds:                   []string{"testdata/crds/crontab_without_default_val.yaml"},
+				input:                  errorReader{},
+				recursive:              false,
 			},
 			expectedExitCode: 1,
 			expectedResults:  []validate.ValidationResult{},
 		},
+		{
+			name: "test directory of schemas named by version",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/configmap_immutable.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions"},
+				crds:                   []string{},
+				recursive:              false,
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "Property 'immutable' is unsupported", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/configmap_immutable.yaml", Schema: "1.17", Document: 0, Line: 1, Column: 1, Constraint: "properties"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/configmap_immutable.yaml", Schema: "1.18", Document: 0},
+			},
+		},
+		{
+			name: "test CRLF documents with commented markers",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/crlf_documents.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+				crds:                   []string{},
+				recursive:              false,
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/crlf_documents.yaml", Document: 0},
+				{Message: "Error at \"/data/replicas\":Field must be set to string or not be present", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "invalid", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/crlf_documents.yaml", Document: 1, Path: "/data/replicas", Line: 18, Column: 3, Constraint: "type", Expected: "string", Actual: "1"},
+			},
+		},
+		{
+			name: "test lists",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/lists.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+				crds:                   []string{},
+				recursive:              false,
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/lists.yaml", Document: 0, Item: intPointer(0)},
+				{Message: "Error at \"/data/replicas\":Field must be set to string or not be present", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "invalid", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/lists.yaml", Document: 0, Item: intPointer(1), Path: "/data/replicas", Line: 17, Column: 5, Constraint: "type", Expected: "string", Actual: "1"},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "typed", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/lists.yaml", Document: 1, Item: intPointer(0)},
+			},
+		},
+		{
+			name: "test removed api versions",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/removed_api.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+				crds:                   []string{},
+				recursive:              false,
+				checkDeprecations:      true,
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/removed_api.yaml", Document: 0},
+				{Message: "Kind 'extensions/v1beta1/DaemonSet' deprecated in 1.9, removed in 1.16, migrate to apps/v1", Severity: validate.SeverityError, Rule: validate.RuleRemovedApi, Name: "node-agent", Namespace: "example", Kind: "extensions/v1beta1/DaemonSet", Source: "testdata/manifests/removed_api.yaml", Document: 1, Line: 9, Column: 1},
+			},
+		},
+		{
+			name: "test suppressed findings",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/suppressed.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+				strict:                 true,
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeveritySuppressed, Rule: validate.RuleUnknownKind, Name: "commented", Kind: "example.io/v1/UnknownCRD", Source: "testdata/manifests/suppressed.yaml", Document: 0, Line: 2, Column: 1},
+				{Message: "Property 'binaryData' is unsupported", Severity: validate.SeveritySuppressed, Rule: validate.RuleSchemaViolation, Name: "annotated", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/suppressed.yaml", Document: 1, Line: 7, Column: 1, Constraint: "properties"},
+				{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "other-rule", Kind: "example.io/v1/UnknownCRD", Source: "testdata/manifests/suppressed.yaml", Document: 2, Line: 19, Column: 1},
+			},
+		},
+		{
+			name: "test invalid metadata",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/invalid_metadata.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "metadata.name: Invalid value: \"My_Settings\": a DNS-1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')", Severity: validate.SeverityError, Rule: validate.RuleMetadataViolation, Name: "My_Settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/invalid_metadata.yaml", Document: 0, Path: "/metadata/name", Line: 4, Column: 3, Actual: "\"My_Settings\""},
+				{Message: "metadata.labels: Invalid value: \"-backend\": a valid label must be an empty string or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyValue',  or 'my_value',  or '12345', regex used for validation is '(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?')", Severity: validate.SeverityError, Rule: validate.RuleMetadataViolation, Name: "My_Settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/invalid_metadata.yaml", Document: 0, Path: "/metadata/labels", Line: 6, Column: 3, Actual: "\"-backend\""},
+			},
+		},
+		{
+			name: "test selector mismatch",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/selector_mismatch.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "spec.template.metadata.labels: Invalid value: \"app=frontend\": `selector` (app=web) does not match template `labels`", Severity: validate.SeverityError, Rule: validate.RuleSelectorMismatch, Name: "web", Namespace: "example", Kind: "apps/v1/Deployment", Source: "testdata/manifests/selector_mismatch.yaml", Document: 0, Path: "/spec/template/metadata/labels", Line: 12, Column: 7, Actual: "\"app=frontend\""},
+			},
+		},
+		{
+			name: "test check references",
+			opts: validateOptions{
+				filenames:              []string{"testdata/references"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				checkReferences:        true,
+				allowedReferences:      []string{"Secret/registry-*"},
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "app-config", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/references/config.yaml", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "app", Namespace: "example", Kind: "apps/v1/Deployment", Source: "testdata/references/deployment.yaml", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "app", Namespace: "example", Kind: "v1/Service", Source: "testdata/references/service.yaml", Document: 0},
+				{Message: "Secret 'app-secrets' not found in the validated resources", Severity: validate.SeverityError, Rule: validate.RuleMissingReference, Name: "app", Namespace: "example", Kind: "apps/v1/Deployment", Source: "testdata/references/deployment.yaml", Document: 0, Path: "/spec/template/spec/containers/0/envFrom/1/secretRef/name", Line: 24, Column: 13},
+			},
+		},
+		{
+			name: "test check selectors",
+			opts: validateOptions{
+				filenames:              []string{"testdata/references"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+				checkSelectors:         true,
+			},
+			expectedExitCode: 0,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "app-config", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/references/config.yaml", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "app", Namespace: "example", Kind: "apps/v1/Deployment", Source: "testdata/references/deployment.yaml", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "app", Namespace: "example", Kind: "v1/Service", Source: "testdata/references/service.yaml", Document: 0},
+				{Message: "Service selector 'app=api' doesn't match the pods of any workload in the validated resources", Severity: validate.SeverityWarning, Rule: validate.RuleUnmatchedSelector, Name: "app", Namespace: "example", Kind: "v1/Service", Source: "testdata/references/service.yaml", Document: 0, Path: "/spec/selector", Line: 7, Column: 3},
+			},
+		},
+		{
+			name: "test references not checked by default",
+			opts: validateOptions{
+				filenames:              []string{"testdata/references/deployment.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+			},
+			expectedExitCode: 0,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "app", Namespace: "example", Kind: "apps/v1/Deployment", Source: "testdata/references/deployment.yaml", Document: 0},
+			},
+		},
+		{
+			name: "test check duplicates",
+			opts: validateOptions{
+				filenames:              []string{"testdata/duplicates"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+				checkDuplicates:        true,
+				defaultNamespace:       "example",
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Kind: "v1/ConfigMap", Source: "testdata/duplicates/base.yaml", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/duplicates/overlay.yaml", Document: 0},
+				{Message: "ConfigMap 'example/settings' is already defined in testdata/duplicates/base.yaml at line 1", Severity: validate.SeverityError, Rule: validate.RuleDuplicateResource, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/duplicates/overlay.yaml", Document: 0, Line: 1, Column: 1},
+			},
+		},
+		{
+			name: "test check duplicates against several schemas",
+			opts: validateOptions{
+				filenames:              []string{"testdata/duplicates"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json", "testdata/schemas/versions/1.18.json"},
+				checkDuplicates:        true,
+				defaultNamespace:       "example",
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Kind: "v1/ConfigMap", Source: "testdata/duplicates/base.yaml", Schema: "testdata/schemas/versions/1.17.json", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Kind: "v1/ConfigMap", Source: "testdata/duplicates/base.yaml", Schema: "testdata/schemas/versions/1.18.json", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/duplicates/overlay.yaml", Schema: "testdata/schemas/versions/1.17.json", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/duplicates/overlay.yaml", Schema: "testdata/schemas/versions/1.18.json", Document: 0},
+				{Message: "ConfigMap 'example/settings' is already defined in testdata/duplicates/base.yaml at line 1", Severity: validate.SeverityError, Rule: validate.RuleDuplicateResource, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/duplicates/overlay.yaml", Document: 0, Line: 1, Column: 1},
+			},
+		},
+		{
+			name: "test duplicates in other namespaces",
+			opts: validateOptions{
+				filenames:              []string{"testdata/duplicates"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+				checkDuplicates:        true,
+				defaultNamespace:       "default",
+			},
+			expectedExitCode: 0,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Kind: "v1/ConfigMap", Source: "testdata/duplicates/base.yaml", Document: 0},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/duplicates/overlay.yaml", Document: 0},
+			},
+		},
+		{
+			name: "test namespace of cluster scoped resources",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/scopes.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/scopes.json"},
+			},
+			expectedExitCode: 0,
+			expectedResults: []validate.ValidationResult{
+				{Message: "Kind 'rbac.authorization.k8s.io/v1/ClusterRole' is cluster scoped, its namespace 'example' is ignored", Severity: validate.SeverityWarning, Rule: validate.RuleUnexpectedNamespace, Name: "reader", Namespace: "example", Kind: "rbac.authorization.k8s.io/v1/ClusterRole", Source: "testdata/manifests/scopes.yaml", Document: 0, Path: "/metadata/namespace", Line: 5, Column: 3},
+				{Message: "valid", Severity: validate.SeverityOK, Name: "writer", Kind: "rbac.authorization.k8s.io/v1/Role", Source: "testdata/manifests/scopes.yaml", Document: 1},
+			},
+		},
+		{
+			name: "test require namespace",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/scopes.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/scopes.json"},
+				requireNamespace:       true,
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "Kind 'rbac.authorization.k8s.io/v1/ClusterRole' is cluster scoped, its namespace 'example' is ignored", Severity: validate.SeverityWarning, Rule: validate.RuleUnexpectedNamespace, Name: "reader", Namespace: "example", Kind: "rbac.authorization.k8s.io/v1/ClusterRole", Source: "testdata/manifests/scopes.yaml", Document: 0, Path: "/metadata/namespace", Line: 5, Column: 3},
+				{Message: "Kind 'rbac.authorization.k8s.io/v1/Role' is namespaced, its namespace is required", Severity: validate.SeverityError, Rule: validate.RuleMissingNamespace, Name: "writer", Kind: "rbac.authorization.k8s.io/v1/Role", Source: "testdata/manifests/scopes.yaml", Document: 1, Path: "/metadata", Line: 9, Column: 1},
+			},
+		},
+		{
+			name: "test multiple schemas",
+			opts: validateOptions{
+				filenames:              []string{"testdata/manifests/configmap_immutable.yaml"},
+				openApiSchemaFilenames: []string{"testdata/schemas/versions/1.18.json", "testdata/schemas/versions/1.17.json"},
+				crds:                   []string{},
+				recursive:              false,
+			},
+			expectedExitCode: 1,
+			expectedResults: []validate.ValidationResult{
+				{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/configmap_immutable.yaml", Schema: "testdata/schemas/versions/1.18.json", Document: 0},
+				{Message: "Property 'immutable' is unsupported", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/configmap_immutable.yaml", Schema: "testdata/schemas/versions/1.17.json", Document: 0, Line: 1, Column: 1, Constraint: "properties"},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -439,3 +808,302 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateJSONOutput(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	opts := validateOptions{
+		filenames:              []string{"testdata/manifests/warn_error.yaml"},
+		openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+		outputFormat:           "json",
+		output:                 stdout,
+		errOutput:              stderr,
+	}
+
+	exitCode, results := runValidate(opts)
+	assert.Equal(t, 1, exitCode)
+
+	var document struct {
+		Results []validate.ValidationResult `json:"results"`
+		Summary report.Summary              `json:"summary"`
+	}
+	err := json.Unmarshal(stdout.Bytes(), &document)
+	assert.NoError(t, err)
+	assert.Equal(t, results, document.Results)
+	assert.Equal(t, report.Summary{Total: 2, Errors: 1, Warnings: 1, Valid: 0, ExitCode: 1}, document.Summary)
+	assert.Contains(t, stderr.String(), "Validating config in testdata/manifests/warn_error.yaml")
+}
+
+func TestValidateUnknownOutputFormat(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	opts := validateOptions{
+		filenames:              []string{"testdata/manifests/warn_error.yaml"},
+		openApiSchemaFilenames: []string{"testdata/schemas/k8s-1.17.0.json"},
+		outputFormat:           "yaml",
+		output:                 &bytes.Buffer{},
+		errOutput:              stderr,
+	}
+
+	exitCode, results := runValidate(opts)
+	assert.Equal(t, 1, exitCode)
+	assert.Empty(t, results)
+	assert.Equal(t, "Unknown output format 'yaml'\n", stderr.String())
+}
+
+func TestValidateSchemasTable(t *testing.T) {
+	output := &bytes.Buffer{}
+	exitCode, _ := runValidate(validateOptions{
+		filenames:              []string{"testdata/manifests/configmap_immutable.yaml"},
+		openApiSchemaFilenames: []string{"testdata/schemas/versions"},
+		output:                 output,
+	})
+
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, output.String(), `Results per schema:
+RESOURCE                                                                      1.17   1.18
+testdata/manifests/configmap_immutable.yaml: example/settings (v1/ConfigMap)  ERROR  OK
+`)
+}
+
+func TestValidateKubernetesVersions(t *testing.T) {
+	storeDir, err := ioutil.TempDir("", "scheriff-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storeDir)
+	schemaStore := store.New(storeDir)
+	for _, version := range []string{"1.17", "1.18"} {
+		_, err = schemaStore.Import(fmt.Sprintf("testdata/schemas/versions/%s.json", version), version)
+		assert.NoError(t, err)
+	}
+
+	exitCode, results := runValidate(validateOptions{
+		filenames:          []string{"testdata/manifests/configmap_immutable.yaml"},
+		kubernetesVersions: []string{"1.17", "v1.18"},
+		schemaStore:        storeDir,
+		output:             &bytes.Buffer{},
+	})
+
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, []validate.ValidationResult{
+		{Message: "Property 'immutable' is unsupported", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/configmap_immutable.yaml", Schema: "1.17", Document: 0, Line: 1, Column: 1, Constraint: "properties"},
+		{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/configmap_immutable.yaml", Schema: "1.18", Document: 0},
+	}, results)
+
+	exitCode, _ = runValidate(validateOptions{
+		filenames:          []string{"testdata/manifests/configmap_immutable.yaml"},
+		kubernetesVersions: []string{"1.25"},
+		schemaStore:        storeDir,
+		output:             &bytes.Buffer{},
+	})
+	assert.Equal(t, 1, exitCode)
+}
+
+func TestSchemaList(t *testing.T) {
+	storeDir, err := ioutil.TempDir("", "scheriff-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storeDir)
+	schemaStore := store.New(storeDir)
+	output := &bytes.Buffer{}
+
+	err = runSchemaList(schemaStore, output)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("No schemas found in %s\n", storeDir), output.String())
+
+	output.Reset()
+	err = runSchemaImport(schemaStore, "testdata/crds/crontab_without_default_val.yaml", "1.18", output)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("Imported testdata/crds/crontab_without_default_val.yaml into %s\n", filepath.Join(storeDir, "1.18", "crds", "crontab_without_default_val.yaml")), output.String())
+
+	output.Reset()
+	err = runSchemaList(schemaStore, output)
+	assert.NoError(t, err)
+	assert.Equal(t, "VERSION  SCHEMA  CRDS\n1.18     -       1\n", output.String())
+}
+
+func TestSchemaCompile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scheriff-compile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	schemaBytes, err := ioutil.ReadFile("testdata/schemas/versions/1.17.json")
+	assert.NoError(t, err)
+	schema := filepath.Join(dir, "1.17.json")
+	assert.NoError(t, ioutil.WriteFile(schema, schemaBytes, 0644))
+	bundle := filepath.Join(dir, "1.17.bundle")
+	output := &bytes.Buffer{}
+
+	err = runSchemaCompile(store.New(dir), schemaCompileOptions{schema: schema, output: bundle}, output, &bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("Compiled %s into %s\n", schema, bundle), output.String())
+
+	opts := validateOptions{
+		filenames:              []string{"testdata/manifests/configmap_immutable.yaml"},
+		openApiSchemaFilenames: []string{schema},
+		output:                 &bytes.Buffer{},
+	}
+	expectedExitCode, expectedResults := runValidate(opts)
+	opts.openApiSchemaFilenames = []string{bundle}
+	exitCode, results := runValidate(opts)
+	assert.Equal(t, expectedExitCode, exitCode)
+	assert.Equal(t, expectedResults, results)
+
+	// stale bundles are rejected
+	assert.NoError(t, ioutil.WriteFile(schema, append(schemaBytes, '\n'), 0644))
+	errOutput := &bytes.Buffer{}
+	opts.output = errOutput
+	exitCode, results = runValidate(opts)
+	assert.Equal(t, 1, exitCode)
+	assert.Empty(t, results)
+	assert.Contains(t, errOutput.String(), "The schema bundle is stale, its sources changed since it was compiled")
+
+	// bundles whose sources can't be read are used with a warning
+	assert.NoError(t, os.Remove(schema))
+	errOutput.Reset()
+	exitCode, results = runValidate(opts)
+	assert.Equal(t, expectedExitCode, exitCode)
+	assert.Equal(t, expectedResults, results)
+	assert.Contains(t, errOutput.String(), fmt.Sprintf("Warning: the checksum of the schema bundle %s can't be verified, its sources can't be read", bundle))
+}
+
+func TestSchemaCompileMovedBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scheriff-compile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	schemaBytes, err := ioutil.ReadFile("testdata/schemas/versions/1.17.json")
+	assert.NoError(t, err)
+	for _, subdir := range []string{"original/schemas", "moved/schemas"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, subdir), 0755))
+	}
+	schema := filepath.Join(dir, "original", "schemas", "1.17.json")
+	assert.NoError(t, ioutil.WriteFile(schema, schemaBytes, 0644))
+	bundle := filepath.Join(dir, "original", "1.17.bundle")
+	err = runSchemaCompile(store.New(dir), schemaCompileOptions{schema: schema, output: bundle}, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.NoError(t, err)
+
+	// the sources are relative to the bundle, so both can be moved together
+	bundleBytes, err := ioutil.ReadFile(bundle)
+	assert.NoError(t, err)
+	compiledBundle, err := validate.ReadSchemaBundle(bundleBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("schemas", "1.17.json")}, compiledBundle.Sources)
+	movedBundle := filepath.Join(dir, "moved", "1.17.bundle")
+	assert.NoError(t, os.Rename(bundle, movedBundle))
+	assert.NoError(t, os.Rename(schema, filepath.Join(dir, "moved", "schemas", "1.17.json")))
+
+	opts := validateOptions{
+		filenames:              []string{"testdata/manifests/configmap_immutable.yaml"},
+		openApiSchemaFilenames: []string{filepath.Join(dir, "moved", "schemas", "1.17.json")},
+		output:                 &bytes.Buffer{},
+	}
+	expectedExitCode, expectedResults := runValidate(opts)
+	errOutput := &bytes.Buffer{}
+	opts.openApiSchemaFilenames = []string{movedBundle}
+	opts.output = errOutput
+	exitCode, results := runValidate(opts)
+	assert.Equal(t, expectedExitCode, exitCode)
+	assert.Equal(t, expectedResults, results)
+	assert.NotContains(t, errOutput.String(), "Warning")
+}
+
+func TestChecksumFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scheriff-checksum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	first := filepath.Join(dir, "first.json")
+	second := filepath.Join(dir, "second.json")
+	checksum := func(firstContent string, secondContent string) string {
+		assert.NoError(t, ioutil.WriteFile(first, []byte(firstContent), 0644))
+		assert.NoError(t, ioutil.WriteFile(second, []byte(secondContent), 0644))
+		checksum, err := checksumFiles([]string{first, second})
+		assert.NoError(t, err)
+		return checksum
+	}
+
+	// the same contents split differently between the files
+	assert.NotEqual(t, checksum("ab", "c"), checksum("a", "bc"))
+	assert.Equal(t, checksum("ab", "c"), checksum("ab", "c"))
+
+	// only the contents are hashed, not the paths of the files
+	expected := checksum("ab", "c")
+	renamed := filepath.Join(dir, "renamed.json")
+	assert.NoError(t, os.Rename(first, renamed))
+	renamedChecksum, err := checksumFiles([]string{renamed, second})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, renamedChecksum)
+
+	_, err = checksumFiles([]string{filepath.Join(dir, "missing.json")})
+	assert.Error(t, err)
+}
+
+func TestSchemaCompileKubernetesVersion(t *testing.T) {
+	storeDir, err := ioutil.TempDir("", "scheriff-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storeDir)
+	schemaStore := store.New(storeDir)
+	_, err = schemaStore.Import("testdata/schemas/versions/1.18.json", "1.18")
+	assert.NoError(t, err)
+
+	err = runSchemaCompile(schemaStore, schemaCompileOptions{kubernetesVersion: "1.18"}, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.NoError(t, err)
+	storedVersion, err := schemaStore.Get("1.18")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaStore.BundlePath("1.18"), storedVersion.Bundle)
+
+	exitCode, results := runValidate(validateOptions{
+		filenames:          []string{"testdata/manifests/configmap_immutable.yaml"},
+		kubernetesVersions: []string{"1.18"},
+		schemaStore:        storeDir,
+		output:             &bytes.Buffer{},
+	})
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, []validate.ValidationResult{
+		{Message: "valid", Severity: validate.SeverityOK, Name: "settings", Namespace: "example", Kind: "v1/ConfigMap", Source: "testdata/manifests/configmap_immutable.yaml", Document: 0},
+	}, results)
+
+	// importing new files invalidates the bundle
+	_, err = schemaStore.Import("testdata/crds/crontab_without_default_val.yaml", "1.18")
+	assert.NoError(t, err)
+	storedVersion, err = schemaStore.Get("1.18")
+	assert.NoError(t, err)
+	assert.Empty(t, storedVersion.Bundle)
+}
+
+func TestSchemaCompileMissingOptions(t *testing.T) {
+	err := runSchemaCompile(store.New("testdata"), schemaCompileOptions{}, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.EqualError(t, err, "Either -s, --schema or --kubernetes-version must be provided")
+	err = runSchemaCompile(store.New("testdata"), schemaCompileOptions{schema: "testdata/schemas/versions/1.17.json"}, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.EqualError(t, err, "The bundle file must be provided with -o, --output")
+}
+
+func TestValidateJobs(t *testing.T) {
+	sequentialOutput := &bytes.Buffer{}
+	opts := validateOptions{
+		filenames:              []string{"testdata/manifests", "-"},
+		openApiSchemaFilenames: []string{"testdata/schemas/versions/1.17.json"},
+		recursive:              true,
+		input:                  openFile(t, "testdata/manifests/configmap_immutable.yaml"),
+		output:                 sequentialOutput,
+		jobs:                   1,
+	}
+	expectedExitCode, expectedResults := runValidate(opts)
+
+	concurrentOutput := &bytes.Buffer{}
+	opts.input = openFile(t, "testdata/manifests/configmap_immutable.yaml")
+	opts.output = concurrentOutput
+	opts.jobs = 8
+	exitCode, results := runValidate(opts)
+
+	assert.Equal(t, expectedExitCode, exitCode)
+	assert.Equal(t, expectedResults, results)
+	assert.Equal(t, sequentialOutput.String(), concurrentOutput.String())
+}
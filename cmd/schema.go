@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/fllaca/scheriff/pkg/fs"
+	"github.com/fllaca/scheriff/pkg/report"
+	"github.com/fllaca/scheriff/pkg/store"
+	"github.com/fllaca/scheriff/pkg/validate"
+	"github.com/spf13/cobra"
+)
+
+type schemaCompileOptions struct {
+	schema            string
+	crds              []string
+	kubernetesVersion string
+	output            string
+}
+
+var (
+	schemaCmd = &cobra.Command{
+		Use:   "schema",
+		Short: "Manage the local store of Kubernetes schemas",
+		Long: `Manage the local store of Kubernetes schemas
+
+The schema store keeps the OpenAPI schema and CustomResourceDefinitions of each Kubernetes version, so they can be used with the --kubernetes-version flag instead of --schema and --crd`,
+	}
+
+	schemaImportVersion string
+	schemaImportCmd     = &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import an OpenAPI schema (or a CRDs YAML file) into the store",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := runSchemaImport(store.New(options.schemaStore), args[0], schemaImportVersion, cmd.OutOrStdout())
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error importing %s: %s\n", args[0], err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	schemaCompileOpts = schemaCompileOptions{}
+	schemaCompileCmd  = &cobra.Command{
+		Use:   "compile",
+		Short: "Compile a schema and its CRDs into a bundle that loads faster",
+		Long: `Compile a schema and its CRDs into a bundle that loads faster
+
+The bundle holds the schemas already prepared for validation, and can be used with -s, --schema like any other schema. It includes a checksum of the files it was compiled from (referenced relative to the bundle), so that it is rejected when they change.
+When compiling a Kubernetes version of the schema store, the bundle is saved in the store and used automatically by --kubernetes-version`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := runSchemaCompile(store.New(options.schemaStore), schemaCompileOpts, cmd.OutOrStdout(), cmd.ErrOrStderr())
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error compiling schema bundle: %s\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	schemaListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the Kubernetes versions available in the store",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := runSchemaList(store.New(options.schemaStore), cmd.OutOrStdout())
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error listing schemas: %s\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&options.schemaStore, "schema-store", store.DefaultDir(), "directory of the local schema store.")
+	schemaImportCmd.Flags().StringVar(&schemaImportVersion, "version", "", "(required) Kubernetes version of the imported schema (ie: 1.24.3)")
+	schemaImportCmd.MarkFlagRequired("version")
+	schemaCompileCmd.Flags().StringVarP(&schemaCompileOpts.schema, "schema", "s", "", "Kubernetes OpenAPI schema to compile: an OpenAPI V2 file, an OpenAPI V3 document, or a directory of OpenAPI V3 group-version documents")
+	schemaCompileCmd.Flags().StringArrayVarP(&schemaCompileOpts.crds, "crd", "c", []string{}, "files or directories that contain CustomResourceDefinitions to include in the bundle")
+	schemaCompileCmd.Flags().StringVar(&schemaCompileOpts.kubernetesVersion, "kubernetes-version", "", "Kubernetes version of the schema store to compile, as an alternative to -s, --schema and -c, --crd")
+	schemaCompileCmd.Flags().StringVarP(&schemaCompileOpts.output, "output", "o", "", "file to write the bundle to (required unless compiling a Kubernetes version of the schema store)")
+	schemaCmd.AddCommand(schemaImportCmd)
+	schemaCmd.AddCommand(schemaCompileCmd)
+	schemaCmd.AddCommand(schemaListCmd)
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func runSchemaImport(schemaStore *store.Store, file string, version string, out io.Writer) error {
+	target, err := schemaStore.Import(file, version)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Imported %s into %s\n", file, target)
+	return nil
+}
+
+func runSchemaList(schemaStore *store.Store, out io.Writer) error {
+	versions, err := schemaStore.List()
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		fmt.Fprintf(out, "No schemas found in %s\n", schemaStore.Dir())
+		return nil
+	}
+	writer := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tSCHEMA\tCRDS")
+	for _, version := range versions {
+		schema := version.Schema
+		if schema == "" {
+			schema = "-"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\n", version.Version, schema, len(version.Crds))
+	}
+	return writer.Flush()
+}
+
+func runSchemaCompile(schemaStore *store.Store, opts schemaCompileOptions, out io.Writer, errOut io.Writer) error {
+	if opts.kubernetesVersion != "" {
+		storedVersion, err := schemaStore.Get(opts.kubernetesVersion)
+		if err != nil {
+			return err
+		}
+		if storedVersion.Schema == "" {
+			return fmt.Errorf("No schema imported for Kubernetes version '%s'", storedVersion.Version)
+		}
+		opts.schema = storedVersion.Schema
+		opts.crds = append(storedVersion.Crds, opts.crds...)
+		if opts.output == "" {
+			opts.output = schemaStore.BundlePath(storedVersion.Version)
+		}
+	}
+	if opts.schema == "" {
+		return fmt.Errorf("Either -s, --schema or --kubernetes-version must be provided")
+	}
+	if opts.output == "" {
+		return fmt.Errorf("The bundle file must be provided with -o, --output")
+	}
+
+	sources, err := listFiles(opts.schema, true, fs.IsJsonFilter)
+	if err != nil {
+		return err
+	}
+	for _, crd := range opts.crds {
+		crdSources, err := listFiles(crd, false, fs.IsYamlFilter)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, crdSources...)
+	}
+	checksum, err := checksumFiles(sources)
+	if err != nil {
+		return err
+	}
+
+	reporter := report.NewTextReporter(errOut)
+	validator, err := newSchemaValidator(reporter, opts.schema)
+	if err != nil {
+		return err
+	}
+	schemaValidators := []schemaValidator{{name: opts.schema, validator: validator}}
+	err = addCrdSchemas(reporter, schemaValidators, opts.crds)
+	if err != nil {
+		return err
+	}
+
+	bundleFile, err := os.Create(opts.output)
+	if err != nil {
+		return err
+	}
+	defer bundleFile.Close()
+	err = validator.Bundle(bundleSources(opts.output, sources), checksum).Write(bundleFile)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Compiled %s into %s\n", opts.schema, opts.output)
+	return bundleFile.Close()
+}
+
+// listFiles returns the absolute paths of the files in 'path' (or 'path' itself if it's a regular file)
+func listFiles(path string, recursive bool, filter fs.FileNameFilter) ([]string, error) {
+	files := make([]string, 0)
+	err := fs.ApplyToPathWithFilter(path, recursive, func(file string) error {
+		absolutePath, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		files = append(files, absolutePath)
+		return nil
+	}, filter)
+	return files, err
+}
+
+// bundleSources returns the paths of the sources relative to the directory of the bundle, so that the bundle keeps
+// working when it's moved along with them (ie: in a repository cloned somewhere else)
+func bundleSources(bundlePath string, sources []string) []string {
+	bundleDir, err := filepath.Abs(filepath.Dir(bundlePath))
+	if err != nil {
+		return sources
+	}
+	relativeSources := make([]string, 0, len(sources))
+	for _, source := range sources {
+		if relativeSource, err := filepath.Rel(bundleDir, source); err == nil {
+			source = relativeSource
+		}
+		relativeSources = append(relativeSources, source)
+	}
+	return relativeSources
+}
+
+// resolveBundleSources returns the paths of the sources of a bundle, resolving the relative ones against its directory
+func resolveBundleSources(bundlePath string, sources []string) []string {
+	resolvedSources := make([]string, 0, len(sources))
+	for _, source := range sources {
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(filepath.Dir(bundlePath), source)
+		}
+		resolvedSources = append(resolvedSources, source)
+	}
+	return resolvedSources
+}
+
+// checksumFiles returns the SHA-256 checksum of the contents of the given files. The length of each file is hashed before
+// its contents, so that the same contents split differently between the files don't have the same checksum.
+func checksumFiles(files []string) (string, error) {
+	hash := sha256.New()
+	for _, file := range files {
+		fileBytes, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "%d\x00", len(fileBytes))
+		hash.Write(fileBytes)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// checkBundleChecksum rejects the bundles whose sources changed since they were compiled. The bundles whose sources can't
+// be read anymore (ie: when only the bundle is distributed) are used anyway, with a warning as they can't be verified.
+func checkBundleChecksum(reporter report.Reporter, bundlePath string, bundle *validate.SchemaBundle) error {
+	checksum, err := checksumFiles(resolveBundleSources(bundlePath, bundle.Sources))
+	if err != nil {
+		reporter.Logf("Warning: the checksum of the schema bundle %s can't be verified, its sources can't be read (%s)\n", bundlePath, err)
+		return nil
+	}
+	if checksum != bundle.Checksum {
+		return fmt.Errorf("The schema bundle is stale, its sources changed since it was compiled: run 'scheriff schema compile' again")
+	}
+	return nil
+}
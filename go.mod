@@ -6,9 +6,11 @@ require (
 	github.com/getkin/kin-openapi v0.19.0
 	github.com/gookit/color v1.2.7
 	github.com/spf13/cobra v0.0.5
+	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.5.1
 	gopkg.in/yaml.v2 v2.3.0
-	gopkg.in/yaml.v3 v3.0.0-20200615113413-eeeca48fe776
+	gopkg.in/yaml.v3 v3.0.1
 	k8s.io/apiextensions-apiserver v0.18.6
+	k8s.io/apimachinery v0.18.6
 	sigs.k8s.io/yaml v1.2.0
 )
@@ -0,0 +1,138 @@
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/fllaca/scheriff/pkg/utils"
+	"github.com/fllaca/scheriff/pkg/validate"
+)
+
+// version is the version of the format of the baseline files
+const version = 1
+
+// Baseline holds the findings known when it was created, so that only new findings are reported afterwards
+type Baseline struct {
+	Version  int       `json:"version"`
+	Findings []Finding `json:"findings"`
+	// pending counts the findings of each fingerprint that haven't been matched yet
+	pending map[Finding]int
+}
+
+// Finding is the fingerprint of a finding: the same finding is found again as long as its file, resource and message don't change
+type Finding struct {
+	File string `json:"file"`
+	Kind string `json:"kind,omitempty"`
+	// Resource is the "namespace/name" of the resource
+	Resource string `json:"resource,omitempty"`
+	Message  string `json:"message"`
+}
+
+// New creates a baseline with the errors and warnings of the results
+func New(results []validate.ValidationResult) *Baseline {
+	baseline := &Baseline{
+		Version:  version,
+		Findings: make([]Finding, 0),
+	}
+	for _, result := range results {
+		if result.Severity == validate.SeverityError || result.Severity == validate.SeverityWarning {
+			baseline.Findings = append(baseline.Findings, fingerprint(result))
+		}
+	}
+	sort.SliceStable(baseline.Findings, func(i, j int) bool {
+		return baseline.Findings[i].less(baseline.Findings[j])
+	})
+	baseline.reset()
+	return baseline
+}
+
+// Load reads a baseline file
+func Load(filename string) (*Baseline, error) {
+	baselineBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	baseline := &Baseline{}
+	err = json.Unmarshal(baselineBytes, baseline)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing baseline %s: %s", filename, err)
+	}
+	if baseline.Version != version {
+		return nil, fmt.Errorf("Unsupported version %d of baseline %s, expected %d", baseline.Version, filename, version)
+	}
+	baseline.reset()
+	return baseline, nil
+}
+
+// Save writes the baseline to a file
+func (baseline *Baseline) Save(filename string) error {
+	baselineBytes, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(baselineBytes, '\n'), 0644)
+}
+
+// Filter removes the findings of the results that are in the baseline, and returns the number of removed findings.
+// Each finding of the baseline only hides one result, so that new occurrences of a known finding are still reported.
+func (baseline *Baseline) Filter(results []validate.ValidationResult) ([]validate.ValidationResult, int) {
+	filtered := make([]validate.ValidationResult, 0, len(results))
+	hidden := 0
+	for _, result := range results {
+		if result.Severity == validate.SeverityError || result.Severity == validate.SeverityWarning {
+			key := fingerprint(result)
+			if baseline.pending[key] > 0 {
+				baseline.pending[key]--
+				hidden++
+				continue
+			}
+		}
+		filtered = append(filtered, result)
+	}
+	return filtered, hidden
+}
+
+// Unmatched returns the number of findings of the baseline that haven't been found by Filter (ie: because they were fixed)
+func (baseline *Baseline) Unmatched() int {
+	unmatched := 0
+	for _, count := range baseline.pending {
+		unmatched += count
+	}
+	return unmatched
+}
+
+func (baseline *Baseline) reset() {
+	baseline.pending = make(map[Finding]int)
+	for _, finding := range baseline.Findings {
+		baseline.pending[finding]++
+	}
+}
+
+func fingerprint(result validate.ValidationResult) Finding {
+	file := result.Source
+	if file != "" && file != validate.StdinSource {
+		file = filepath.ToSlash(filepath.Clean(file))
+	}
+	return Finding{
+		File:     file,
+		Kind:     result.Kind,
+		Resource: utils.JoinNotEmptyStrings("/", result.Namespace, result.Name),
+		Message:  result.Message,
+	}
+}
+
+func (finding Finding) less(other Finding) bool {
+	if finding.File != other.File {
+		return finding.File < other.File
+	}
+	if finding.Kind != other.Kind {
+		return finding.Kind < other.Kind
+	}
+	if finding.Resource != other.Resource {
+		return finding.Resource < other.Resource
+	}
+	return finding.Message < other.Message
+}
@@ -0,0 +1,85 @@
+package baseline
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fllaca/scheriff/pkg/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	unknownKind = validate.ValidationResult{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "unknown", Namespace: "example", Kind: "example.io/v1/UnknownCRD", Source: "./manifests/crds.yaml", Line: 1}
+	violation   = validate.ValidationResult{Message: "Property 'immutable' is unsupported", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "settings", Kind: "v1/ConfigMap", Source: "manifests/configmap.yaml", Line: 1}
+	valid       = validate.ValidationResult{Message: "valid", Severity: validate.SeverityOK, Name: "other", Kind: "v1/ConfigMap", Source: "manifests/configmap.yaml"}
+)
+
+func TestNew(t *testing.T) {
+	baseline := New([]validate.ValidationResult{unknownKind, valid, violation})
+
+	assert.Equal(t, []Finding{
+		{File: "manifests/configmap.yaml", Kind: "v1/ConfigMap", Resource: "settings", Message: "Property 'immutable' is unsupported"},
+		{File: "manifests/crds.yaml", Kind: "example.io/v1/UnknownCRD", Resource: "example/unknown", Message: "Kind 'example.io/v1/UnknownCRD' not found in schema"},
+	}, baseline.Findings)
+}
+
+func TestFilter(t *testing.T) {
+	baseline := New([]validate.ValidationResult{unknownKind, violation})
+
+	// the line of the finding isn't part of its fingerprint
+	movedViolation := violation
+	movedViolation.Line = 10
+	otherViolation := violation
+	otherViolation.Name = "other"
+	results, hidden := baseline.Filter([]validate.ValidationResult{valid, movedViolation, otherViolation, violation})
+
+	assert.Equal(t, []validate.ValidationResult{valid, otherViolation, violation}, results)
+	assert.Equal(t, 1, hidden)
+	assert.Equal(t, 1, baseline.Unmatched())
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scheriff-baseline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "baseline.json")
+
+	err = New([]validate.ValidationResult{unknownKind, violation}).Save(filename)
+	assert.NoError(t, err)
+	baseline, err := Load(filename)
+	assert.NoError(t, err)
+
+	results, hidden := baseline.Filter([]validate.ValidationResult{unknownKind, violation})
+	assert.Empty(t, results)
+	assert.Equal(t, 2, hidden)
+	assert.Equal(t, 0, baseline.Unmatched())
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scheriff-baseline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{content: "[]", expected: "Error parsing baseline %s: json: cannot unmarshal array into Go value of type baseline.Baseline"},
+		{content: `{"version": 2, "findings": []}`, expected: "Unsupported version 2 of baseline %s, expected 1"},
+	}
+
+	for i, test := range tests {
+		filename := filepath.Join(dir, fmt.Sprintf("baseline-%d.json", i))
+		err := ioutil.WriteFile(filename, []byte(test.content), 0644)
+		assert.NoError(t, err)
+		_, err = Load(filename)
+		assert.EqualError(t, err, fmt.Sprintf(test.expected, filename))
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fllaca/scheriff/pkg/fs"
+	"github.com/fllaca/scheriff/pkg/validate"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Filename is the name of the configuration file, looked up from the working directory upwards
+const Filename = ".scheriff.yaml"
+
+// Config holds the options of a project, so they don't need to be given as flags on every run.
+// Relative paths are relative to the directory of the configuration file.
+type Config struct {
+	// Schemas are the OpenAPI schemas to validate against (-s, --schema)
+	Schemas []string `yaml:"schemas,omitempty"`
+	// KubernetesVersions are the versions of the schema store to validate against (--kubernetes-version)
+	KubernetesVersions []string `yaml:"kubernetesVersions,omitempty"`
+	// Crds are the files or directories with CustomResourceDefinitions (-c, --crd)
+	Crds []string `yaml:"crds,omitempty"`
+	// Files are the files or directories to validate (-f, --filename)
+	Files     []string `yaml:"files,omitempty"`
+	Recursive bool     `yaml:"recursive,omitempty"`
+	Include   []string `yaml:"include,omitempty"`
+	Exclude   []string `yaml:"exclude,omitempty"`
+	Strict    bool     `yaml:"strict,omitempty"`
+	Output    string   `yaml:"output,omitempty"`
+	// Baseline is the baseline file of known findings (--baseline)
+	Baseline string `yaml:"baseline,omitempty"`
+	// RequireNamespace reports the namespaced resources without namespace (--require-namespace)
+	RequireNamespace bool `yaml:"requireNamespace,omitempty"`
+	// CheckReferences enables the checks of the references between resources (--check-references)
+	CheckReferences bool `yaml:"checkReferences,omitempty"`
+	// AllowedReferences are the glob patterns of "Kind/name" of the objects managed outside of the project (--allow-reference)
+	AllowedReferences []string `yaml:"allowedReferences,omitempty"`
+	// CheckSelectors enables the checks of the selectors of the Services against the validated pods (--check-selectors)
+	CheckSelectors bool `yaml:"checkSelectors,omitempty"`
+	// CheckDuplicates enables the detection of resources defined more than once (--check-duplicates)
+	CheckDuplicates bool `yaml:"checkDuplicates,omitempty"`
+	// DefaultNamespace is the namespace of the resources without namespace (--default-namespace)
+	DefaultNamespace string `yaml:"defaultNamespace,omitempty"`
+	// Overrides change the severity of the findings of a rule for some kinds
+	Overrides []Override `yaml:"overrides,omitempty"`
+}
+
+// Override changes the severity of the findings of a rule for the kinds matching a glob pattern
+type Override struct {
+	// Kind is a glob pattern of "apiVersion/kind" (ie: "monitoring.coreos.com/*/*" or "**/Secret")
+	Kind string `yaml:"kind"`
+	// Rule is the overridden rule (ie: "unknown-kind"), all of them when empty
+	Rule string `yaml:"rule,omitempty"`
+	// Severity is one of "error", "warning" or "off" (to ignore the findings)
+	Severity string `yaml:"severity"`
+}
+
+// Find looks for the configuration file in 'start' and its parents, and returns its path (relative to 'start' if it's relative)
+// or an empty string if there is none
+func Find(start string) (string, error) {
+	absoluteStart, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+	dir := absoluteStart
+	for {
+		candidate := filepath.Join(dir, Filename)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			if filepath.IsAbs(start) {
+				return candidate, nil
+			}
+			relativePath, err := filepath.Rel(absoluteStart, candidate)
+			if err != nil {
+				return "", err
+			}
+			return filepath.Join(start, relativePath), nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads a configuration file, resolving its relative paths
+func Load(filename string) (*Config, error) {
+	configBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	decoder := yamlv3.NewDecoder(bytes.NewReader(configBytes))
+	decoder.KnownFields(true)
+	err = decoder.Decode(config)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("Error parsing %s: %s", filename, err)
+	}
+	_, err = config.RuleOverrides()
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %s", filename, err)
+	}
+
+	dir := filepath.Dir(filename)
+	config.Schemas = resolvePaths(dir, config.Schemas)
+	config.Crds = resolvePaths(dir, config.Crds)
+	config.Files = resolvePaths(dir, config.Files)
+	if config.Baseline != "" {
+		config.Baseline = resolvePaths(dir, []string{config.Baseline})[0]
+	}
+	return config, nil
+}
+
+// RuleOverrides returns the overrides of the configuration as validate.RuleOverrides
+func (config *Config) RuleOverrides() ([]validate.RuleOverride, error) {
+	overrides := make([]validate.RuleOverride, 0, len(config.Overrides))
+	for _, override := range config.Overrides {
+		if override.Kind == "" {
+			return nil, fmt.Errorf("The kind of the overrides is required")
+		}
+		if err := fs.ValidateGlob(override.Kind); err != nil {
+			return nil, err
+		}
+		if _, ok := validate.RuleDescriptions[override.Rule]; override.Rule != "" && !ok {
+			return nil, fmt.Errorf("Unknown rule '%s' in the overrides of '%s'", override.Rule, override.Kind)
+		}
+		severity, err := parseSeverity(override.Severity)
+		if err != nil {
+			return nil, fmt.Errorf("%s in the overrides of '%s'", err, override.Kind)
+		}
+		overrides = append(overrides, validate.RuleOverride{Kind: override.Kind, Rule: override.Rule, Severity: severity})
+	}
+	return overrides, nil
+}
+
+func parseSeverity(severity string) (validate.Severity, error) {
+	switch strings.ToLower(severity) {
+	case "error":
+		return validate.SeverityError, nil
+	case "warning", "warn":
+		return validate.SeverityWarning, nil
+	case "off":
+		return validate.SeverityOK, nil
+	default:
+		return "", fmt.Errorf("Invalid severity '%s', expected one of: error|warning|off", severity)
+	}
+}
+
+// resolvePaths makes the relative paths relative to 'dir', keeping "-" (stdin) as it is
+func resolvePaths(dir string, paths []string) []string {
+	resolved := make([]string, len(paths))
+	for i, path := range paths {
+		if path == "-" || filepath.IsAbs(path) {
+			resolved[i] = path
+			continue
+		}
+		resolved[i] = filepath.Join(dir, path)
+	}
+	return resolved
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/fllaca/scheriff/pkg/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFind(t *testing.T) {
+	configFile, err := Find("testdata/project/app")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("testdata", "project", Filename), configFile)
+
+	absoluteStart, err := filepath.Abs("testdata/project/app")
+	assert.NoError(t, err)
+	configFile, err = Find(absoluteStart)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(filepath.Dir(absoluteStart), Filename), configFile)
+
+	// there's no configuration file in the root directory of the filesystem (or its parents)
+	configFile, err = Find("/")
+	assert.NoError(t, err)
+	assert.Equal(t, "", configFile)
+}
+
+func TestLoad(t *testing.T) {
+	config, err := Load("testdata/project/.scheriff.yaml")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"testdata/project/schemas/k8s-1.17.0.json", "/opt/schemas/k8s-1.18.0.json"}, config.Schemas)
+	assert.Equal(t, []string{"testdata/project/crds"}, config.Crds)
+	assert.Equal(t, []string{"testdata/project/manifests", "-"}, config.Files)
+	assert.True(t, config.Strict)
+	assert.Equal(t, "testdata/project/.scheriff-baseline.json", config.Baseline)
+	assert.True(t, config.RequireNamespace)
+	assert.True(t, config.CheckReferences)
+	assert.Equal(t, []string{"Secret/registry-*"}, config.AllowedReferences)
+	assert.True(t, config.CheckSelectors)
+	assert.True(t, config.CheckDuplicates)
+	assert.Equal(t, "example", config.DefaultNamespace)
+	assert.False(t, config.Recursive)
+
+	overrides, err := config.RuleOverrides()
+	assert.NoError(t, err)
+	assert.Equal(t, []validate.RuleOverride{
+		{Kind: "**/ServiceMonitor", Severity: validate.SeverityOK},
+		{Kind: "**/Secret", Rule: validate.RuleSchemaViolation, Severity: validate.SeverityWarning},
+	}, overrides)
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{file: "testdata/unknown_field.yaml", expected: "Error parsing testdata/unknown_field.yaml: yaml: unmarshal errors:\n  line 1: field schema not found in type config.Config"},
+		{file: "testdata/invalid_severity.yaml", expected: "Error parsing testdata/invalid_severity.yaml: Invalid severity 'fatal', expected one of: error|warning|off in the overrides of '**/Secret'"},
+		{file: "testdata/unknown_rule.yaml", expected: "Error parsing testdata/unknown_rule.yaml: Unknown rule 'not-a-rule' in the overrides of '**/Secret'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			_, err := Load(test.file)
+			assert.EqualError(t, err, test.expected)
+		})
+	}
+}
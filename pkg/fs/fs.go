@@ -3,6 +3,7 @@ package fs
 import (
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -10,18 +11,33 @@ import (
 type FileFunc func(filename string) error
 type FileNameFilter func(filename string) bool
 
+// WalkOptions select the files visited in folders, besides the ones skipped by the '.scheriffignore' files found in them
+type WalkOptions struct {
+	// Recursive visits the subfolders too
+	Recursive bool
+	// Include are the glob patterns (see MatchGlob) of the files to visit, all of them when empty
+	Include []string
+	// Exclude are the glob patterns of the files (or folders) to skip
+	Exclude []string
+}
+
 // ApplyToPathWithFilter executes a 'FileFunc' function for each file in a given 'path'.
 // If 'path' is a regular file itself 'FileFunc' will be applied to it directly without filtering.
 // If 'path' is a folder, the function will be applied to each regular file inside the folder that matches the `FileNameFilter`.
 // This behaviour can be made recursive by setting 'recursive' to true.
 func ApplyToPathWithFilter(path string, recursive bool, function FileFunc, filter FileNameFilter) error {
+	return ApplyToPathWithOptions(path, WalkOptions{Recursive: recursive}, function, filter)
+}
+
+// ApplyToPathWithOptions works like ApplyToPathWithFilter, selecting the files visited in folders with 'options'
+func ApplyToPathWithOptions(path string, options WalkOptions, function FileFunc, filter FileNameFilter) error {
 	fileInfo, err := os.Stat(path)
 	if err != nil {
 		return err
 	}
 
 	if fileInfo.IsDir() {
-		return ApplyToFolder(path, recursive, function, filter)
+		return applyToFolder(path, options, function, filter)
 	}
 
 	return ApplyToFile(path, function, nil)
@@ -36,7 +52,11 @@ func ApplyToFile(path string, function FileFunc, filter FileNameFilter) error {
 }
 
 func ApplyToFolder(folder string, recursive bool, function FileFunc, filter FileNameFilter) error {
-	filenames, err := getFolderFilenames(folder, recursive)
+	return applyToFolder(folder, WalkOptions{Recursive: recursive}, function, filter)
+}
+
+func applyToFolder(folder string, options WalkOptions, function FileFunc, filter FileNameFilter) error {
+	filenames, err := getFolderFilenames(folder, options)
 	if err != nil {
 		return err
 	}
@@ -51,31 +71,57 @@ func ApplyToFolder(folder string, recursive bool, function FileFunc, filter File
 	return nil
 }
 
-func getFolderFilenames(folder string, recursive bool) ([]string, error) {
+func getFolderFilenames(folder string, options WalkOptions) ([]string, error) {
 	var filenames []string = make([]string, 0)
-	if recursive {
-		err := filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() {
-				filenames = append(filenames, path)
+	err := walkFolder(folder, "", options, ignoreRules{}, &filenames)
+	if err != nil {
+		return nil, err
+	}
+	return filenames, nil
+}
+
+// walkFolder appends the files of 'dir' (relative to the walked 'folder', and slash-separated) that aren't skipped by the
+// options or the ignore files of 'dir' and its parents
+func walkFolder(folder string, dir string, options WalkOptions, rules ignoreRules, filenames *[]string) error {
+	rules, err := rules.readIgnoreFile(folder, dir)
+	if err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(filepath.Join(folder, filepath.FromSlash(dir)))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		relativePath := path.Join(dir, file.Name())
+		filename := filepath.Join(folder, filepath.FromSlash(relativePath))
+		if rules.ignored(relativePath, file.IsDir()) || matchesAnyGlob(options.Exclude, relativePath, filename) {
+			continue
+		}
+		if file.IsDir() {
+			if options.Recursive {
+				err = walkFolder(folder, relativePath, options, rules, filenames)
+				if err != nil {
+					return err
+				}
 			}
-			return nil
-		})
-		if err != nil {
-			return nil, err
+			continue
 		}
-	} else {
-		files, err := ioutil.ReadDir(folder)
-		if err != nil {
-			return nil, err
+		if len(options.Include) > 0 && !matchesAnyGlob(options.Include, relativePath, filename) {
+			continue
 		}
-		for _, file := range files {
-			filenames = append(filenames, filepath.Join(folder, file.Name()))
+		*filenames = append(*filenames, filename)
+	}
+	return nil
+}
+
+// matchesAnyGlob tells if a file matches any of the patterns, either by its path relative to the walked folder or by its full path
+func matchesAnyGlob(patterns []string, relativePath string, filename string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, relativePath) || MatchGlob(pattern, filepath.ToSlash(filename)) {
+			return true
 		}
 	}
-	return filenames, nil
+	return false
 }
 
 func IsYamlFilter(filename string) bool {
@@ -87,3 +133,12 @@ func IsYamlFilter(filename string) bool {
 	}
 	return false
 }
+
+func IsJsonFilter(filename string) bool {
+	return strings.HasSuffix(filename, ".json")
+}
+
+// IsManifestFilter accepts the files that can contain Kubernetes manifests (YAML or JSON files)
+func IsManifestFilter(filename string) bool {
+	return IsYamlFilter(filename) || IsJsonFilter(filename)
+}
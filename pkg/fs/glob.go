@@ -0,0 +1,47 @@
+package fs
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether a slash-separated path matches a glob pattern. Each segment of the pattern follows
+// the syntax of path.Match ('*', '?', '[...]'), and a "**" segment matches any number of segments, including none
+// (ie: "**/kustomization.yaml" matches "kustomization.yaml" and "overlays/prod/kustomization.yaml").
+// Invalid patterns don't match any path, use ValidateGlob to check them.
+func MatchGlob(pattern string, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// ValidateGlob returns an error if the pattern isn't a valid glob pattern
+func ValidateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("Invalid glob pattern '%s': %s", pattern, err)
+		}
+	}
+	return nil
+}
+
+func matchSegments(patterns []string, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for i := 0; i <= len(names); i++ {
+				if matchSegments(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		matched, err := path.Match(patterns[0], names[0])
+		if err != nil || !matched {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0
+}
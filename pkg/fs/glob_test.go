@@ -0,0 +1,75 @@
+package fs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "*.yaml", name: "deployment.yaml", expected: true},
+		{pattern: "*.yaml", name: "app/deployment.yaml", expected: false},
+		{pattern: "app/*.y*ml", name: "app/deployment.yml", expected: true},
+		{pattern: "**/kustomization.yaml", name: "kustomization.yaml", expected: true},
+		{pattern: "**/kustomization.yaml", name: "overlays/prod/kustomization.yaml", expected: true},
+		{pattern: "overlays/**", name: "overlays/prod/patch.yaml", expected: true},
+		{pattern: "overlays/**/patch.yaml", name: "overlays/patch.yaml", expected: true},
+		{pattern: "overlays/**/patch.yaml", name: "base/patch.yaml", expected: false},
+		{pattern: "**/test/**/*.json", name: "a/test/b/c/fixture.json", expected: true},
+		{pattern: "**/test/**/*.json", name: "a/tests/fixture.json", expected: false},
+		{pattern: "[ab]/*.yaml", name: "c/x.yaml", expected: false},
+		{pattern: "[a", name: "a", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, MatchGlob(test.pattern, test.name))
+		})
+	}
+}
+
+func TestValidateGlob(t *testing.T) {
+	assert.NoError(t, ValidateGlob("**/[a-z]*.yaml"))
+	assert.EqualError(t, ValidateGlob("app/[a"), "Invalid glob pattern 'app/[a': syntax error in pattern")
+}
+
+func TestIgnoreRules(t *testing.T) {
+	rules := ignoreRules{}
+	for _, line := range []string{"# comment", "", "*.json", "/build/", "!keep.json", "docs/**/*.yaml", "\\#weird"} {
+		if rule, ok := parseIgnoreRule(line, ""); ok {
+			rules = append(rules, rule)
+		}
+	}
+	if rule, ok := parseIgnoreRule("values.yaml", "charts"); ok {
+		rules = append(rules, rule)
+	}
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{path: "schema.json", expected: true},
+		{path: "app/schema.json", expected: true},
+		{path: "app/keep.json", expected: false},
+		{path: "build", isDir: true, expected: true},
+		{path: "build", isDir: false, expected: false},
+		{path: "app/build", isDir: true, expected: false},
+		{path: "docs/examples/deployment.yaml", expected: true},
+		{path: "#weird", expected: true},
+		{path: "charts/app/values.yaml", expected: true},
+		{path: "values.yaml", expected: false},
+		{path: "deployment.yaml", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			assert.Equal(t, test.expected, rules.ignored(test.path, test.isDir))
+		})
+	}
+}
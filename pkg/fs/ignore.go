@@ -0,0 +1,93 @@
+package fs
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFilename is the name of the files that list the paths to skip when walking the directory they are in (and its subdirectories)
+const IgnoreFilename = ".scheriffignore"
+
+// ignoreRule is a pattern of an ignore file, following the semantics of .gitignore files
+type ignoreRule struct {
+	pattern string
+	// base is the directory of the ignore file, relative to the walked folder
+	base string
+	// negate re-includes the paths matched by the previous rules ("!pattern")
+	negate bool
+	// dirOnly only matches directories ("pattern/")
+	dirOnly bool
+}
+
+type ignoreRules []ignoreRule
+
+// readIgnoreFile appends the rules of the ignore file in 'dir' (relative to the walked 'root'), if it exists
+func (rules ignoreRules) readIgnoreFile(root string, dir string) (ignoreRules, error) {
+	file, err := os.Open(filepath.Join(root, filepath.FromSlash(dir), IgnoreFilename))
+	if os.IsNotExist(err) {
+		return rules, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// copied so that the rules of sibling directories don't share the appended elements
+	dirRules := append(make(ignoreRules, 0, len(rules)), rules...)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		rule, ok := parseIgnoreRule(scanner.Text(), dir)
+		if ok {
+			dirRules = append(dirRules, rule)
+		}
+	}
+	return dirRules, scanner.Err()
+}
+
+// parseIgnoreRule parses a line of an ignore file, returning false for blank lines and comments
+func parseIgnoreRule(line string, base string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	// escaped leading characters ("\#file", "\!file")
+	line = strings.TrimPrefix(line, "\\")
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// patterns without a slash match at any depth, the others are relative to the directory of the ignore file
+	if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+	rule.pattern = strings.TrimPrefix(line, "/")
+	return rule, rule.pattern != ""
+}
+
+// ignored tells if a path (relative to the walked folder) is ignored. The last matching rule wins.
+func (rules ignoreRules) ignored(relativePath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		name := relativePath
+		if rule.base != "" {
+			if !strings.HasPrefix(relativePath, rule.base+"/") {
+				continue
+			}
+			name = strings.TrimPrefix(relativePath, rule.base+"/")
+		}
+		if MatchGlob(rule.pattern, name) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,229 @@
+package kubernetes
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	yamlv3 "gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
+)
+
+// yamlErrorLineRegexp matches the line number reported in YAML syntax errors
+var yamlErrorLineRegexp = regexp.MustCompile(`yaml: line (\d+):`)
+
+// YamlDocument is a document read from a stream of YAML documents
+type YamlDocument struct {
+	// Index is the position of the document in the stream, starting at 0
+	Index int
+	// Line is the line of the stream where the document starts (the line of its "---" marker, if any)
+	Line int
+	// Node is the root node of the document, with the lines of the nodes relative to the stream. It's nil for empty documents.
+	Node *yamlv3.Node
+	// Resource is the content of the document, with the same values it would get when applied to Kubernetes
+	Resource Resource
+	// HeadComments are the comments before the content of the document (including the one of its "---" marker line),
+	// without the leading '#' and spaces
+	HeadComments []string
+}
+
+// DocumentError is an error found when decoding one of the documents of a stream, the next documents can still be decoded
+type DocumentError struct {
+	Index int
+	// Line of the stream where the error is found, or 0 when unknown
+	Line int
+	Err  error
+}
+
+func (documentError *DocumentError) Error() string {
+	return documentError.Err.Error()
+}
+
+// YamlDecoder decodes the documents of a YAML stream one by one, without reading the whole stream in memory.
+//
+// Unlike yaml.v3's Decoder, it can go on after a document with a syntax error: documents are delimited by their
+// "---" markers first (which can't appear at the start of a line inside any YAML scalar), and then decoded on their own.
+//
+// Streams starting with '{' or '[' are decoded as JSON instead: a sequence of JSON resources (ie: JSON lines), where
+// the items of top-level arrays are decoded as separate documents.
+type YamlDecoder struct {
+	reader *bufio.Reader
+	// json decodes the stream when it's JSON, it's only set after reading the first document
+	json     *jsonDecoder
+	detected bool
+	// line is the line of the stream that will be read next
+	line  int
+	index int
+	// marker is the remainder of the "---" line that starts the next document, if found
+	marker []byte
+	err    error
+}
+
+func NewYamlDecoder(reader io.Reader) *YamlDecoder {
+	return &YamlDecoder{
+		reader: bufio.NewReader(reader),
+		line:   1,
+	}
+}
+
+// Next returns the next document of the stream. It returns a *DocumentError when the document can't be decoded,
+// io.EOF at the end of the stream, and any other error when the stream can't be read.
+func (decoder *YamlDecoder) Next() (*YamlDocument, error) {
+	if !decoder.detected {
+		decoder.detected = true
+		if isJson(decoder.reader) {
+			decoder.json = newJsonDecoder(decoder.reader)
+		}
+	}
+	if decoder.json != nil {
+		return decoder.json.next()
+	}
+	for {
+		if decoder.err != nil {
+			return nil, decoder.err
+		}
+		// the content before the first "---" marker is only a document when it isn't empty
+		isPreamble := decoder.line == 1 && decoder.marker == nil
+		startLine := decoder.line
+		documentBytes, err := decoder.readDocument()
+		if err != nil {
+			decoder.err = err
+			return nil, err
+		}
+		document, err := decoder.decode(documentBytes, startLine)
+		if err == nil && document.Node == nil && isPreamble {
+			continue
+		}
+		decoder.index++
+		return document, err
+	}
+}
+
+// readDocument reads the lines of the stream until the next "---" marker, or the end of the stream
+func (decoder *YamlDecoder) readDocument() ([]byte, error) {
+	documentBytes := make([]byte, 0)
+	if decoder.marker != nil {
+		// the marker line is kept (blanked) so that the lines of the document match the ones of the stream
+		documentBytes = append(documentBytes, bytes.Repeat([]byte(" "), 3)...)
+		documentBytes = append(documentBytes, decoder.marker...)
+		decoder.marker = nil
+		decoder.line++
+	}
+	for {
+		line, err := decoder.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if isDocumentMarker(line) {
+			decoder.marker = line[3:]
+			return documentBytes, nil
+		}
+		documentBytes = append(documentBytes, line...)
+		if err == io.EOF {
+			decoder.err = io.EOF
+			return documentBytes, nil
+		}
+		decoder.line++
+	}
+}
+
+// decode decodes a single document, given the line of the stream where it starts
+func (decoder *YamlDecoder) decode(documentBytes []byte, startLine int) (*YamlDocument, error) {
+	node := &yamlv3.Node{}
+	err := yamlv3.Unmarshal(documentBytes, node)
+	if err != nil {
+		return nil, decoder.documentError(err, startLine)
+	}
+	document := &YamlDocument{
+		Index:        decoder.index,
+		Line:         startLine,
+		Resource:     Resource{},
+		HeadComments: headComments(documentBytes),
+	}
+	if node.Kind != yamlv3.DocumentNode || len(node.Content) == 0 {
+		return document, nil
+	}
+	document.Node = node.Content[0]
+	shiftPosition(document.Node, startLine, 1, make(map[*yamlv3.Node]bool))
+
+	// the resource is read with sigs.k8s.io/yaml (as kubectl does) so that its values match the ones Kubernetes would get
+	err = yaml.Unmarshal(documentBytes, &document.Resource)
+	if err != nil {
+		return nil, &DocumentError{Index: decoder.index, Line: document.Node.Line, Err: err}
+	}
+	return document, nil
+}
+
+// documentError builds the DocumentError of a syntax error, with the line reported by the error relative to the stream
+func (decoder *YamlDecoder) documentError(err error, startLine int) *DocumentError {
+	documentError := &DocumentError{Index: decoder.index, Err: err}
+	match := yamlErrorLineRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return documentError
+	}
+	line, _ := strconv.Atoi(match[1])
+	documentError.Line = startLine + line - 1
+	documentError.Err = fmt.Errorf("%s", yamlErrorLineRegexp.ReplaceAllString(err.Error(), fmt.Sprintf("yaml: line %d:", documentError.Line)))
+	return documentError
+}
+
+// headComments returns the comment lines found before the first line with content of a document
+func headComments(documentBytes []byte) []string {
+	comments := make([]string, 0)
+	for _, line := range bytes.Split(documentBytes, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != '#' {
+			break
+		}
+		comments = append(comments, string(bytes.TrimSpace(bytes.TrimLeft(line, "#"))))
+	}
+	return comments
+}
+
+// isJson tells if a stream is JSON, by its first character other than whitespace
+func isJson(reader *bufio.Reader) bool {
+	for size := 1; ; size++ {
+		peeked, _ := reader.Peek(size)
+		if len(peeked) < size {
+			return false
+		}
+		switch peeked[size-1] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{', '[':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// isDocumentMarker tells if a line starts a new document ("---", optionally followed by content or a comment)
+func isDocumentMarker(line []byte) bool {
+	if !bytes.HasPrefix(line, []byte("---")) {
+		return false
+	}
+	return len(line) == 3 || line[3] == ' ' || line[3] == '\t' || line[3] == '\r' || line[3] == '\n'
+}
+
+// shiftPosition moves the position of a node and all its descendants (relative to the start of their document)
+// to the position of the stream where the document starts
+func shiftPosition(node *yamlv3.Node, line int, column int, visited map[*yamlv3.Node]bool) {
+	if visited[node] {
+		return
+	}
+	visited[node] = true
+	if node.Line == 1 {
+		node.Column += column - 1
+	}
+	node.Line += line - 1
+	for _, child := range node.Content {
+		shiftPosition(child, line, column, visited)
+	}
+}
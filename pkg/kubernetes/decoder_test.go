@@ -0,0 +1,207 @@
+package kubernetes
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// decodedDocument holds the fields of a YamlDocument (or DocumentError) compared by the tests
+type decodedDocument struct {
+	index    int
+	line     int
+	rootLine int
+	name     string
+	err      string
+}
+
+func decodeAll(t *testing.T, input string) []decodedDocument {
+	documents := make([]decodedDocument, 0)
+	decoder := NewYamlDecoder(strings.NewReader(input))
+	for {
+		document, err := decoder.Next()
+		if err == io.EOF {
+			return documents
+		}
+		if documentError, ok := err.(*DocumentError); ok {
+			documents = append(documents, decodedDocument{index: documentError.Index, line: documentError.Line, err: documentError.Error()})
+			continue
+		}
+		if !assert.NoError(t, err) {
+			return documents
+		}
+		decoded := decodedDocument{index: document.Index, line: document.Line, name: GetName(document.Resource)}
+		if document.Node != nil {
+			decoded.rootLine = document.Node.Line
+		}
+		documents = append(documents, decoded)
+	}
+}
+
+func TestYamlDecoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []decodedDocument
+	}{
+		{
+			name:  "single document",
+			input: "kind: ConfigMap\nmetadata:\n  name: a\n",
+			expected: []decodedDocument{
+				{index: 0, line: 1, rootLine: 1, name: "a"},
+			},
+		},
+		{
+			name:  "marker on the first line",
+			input: "---\nkind: ConfigMap\nmetadata:\n  name: a\n---\nkind: ConfigMap\nmetadata:\n  name: b\n",
+			expected: []decodedDocument{
+				{index: 0, line: 1, rootLine: 2, name: "a"},
+				{index: 1, line: 5, rootLine: 6, name: "b"},
+			},
+		},
+		{
+			name:  "CRLF line endings",
+			input: "kind: ConfigMap\r\nmetadata:\r\n  name: a\r\n---\r\nkind: ConfigMap\r\nmetadata:\r\n  name: b\r\n",
+			expected: []decodedDocument{
+				{index: 0, line: 1, rootLine: 1, name: "a"},
+				{index: 1, line: 4, rootLine: 5, name: "b"},
+			},
+		},
+		{
+			name:  "markers with comments and content",
+			input: "--- # first\nkind: ConfigMap\nmetadata:\n  name: a\n--- {kind: ConfigMap, metadata: {name: b}}\n",
+			expected: []decodedDocument{
+				{index: 0, line: 1, rootLine: 2, name: "a"},
+				{index: 1, line: 5, rootLine: 5, name: "b"},
+			},
+		},
+		{
+			name:  "markers inside block scalars",
+			input: "kind: ConfigMap\nmetadata:\n  name: a\ndata:\n  script: |\n    ---\n    echo\n---\nkind: ConfigMap\nmetadata:\n  name: b\n",
+			expected: []decodedDocument{
+				{index: 0, line: 1, rootLine: 1, name: "a"},
+				{index: 1, line: 8, rootLine: 9, name: "b"},
+			},
+		},
+		{
+			name:  "empty documents",
+			input: "# header\n---\n---\n# nothing\n---\nkind: ConfigMap\nmetadata:\n  name: a\n",
+			expected: []decodedDocument{
+				{index: 0, line: 2},
+				{index: 1, line: 3},
+				{index: 2, line: 5, rootLine: 6, name: "a"},
+			},
+		},
+		{
+			name:  "documents after a syntax error",
+			input: "kind: ConfigMap\nmetadata:\n  name: a\n---\nthis\n is:\n    wrong\n---\nkind: ConfigMap\nmetadata:\n  name: b\n",
+			expected: []decodedDocument{
+				{index: 0, line: 1, rootLine: 1, name: "a"},
+				{index: 1, line: 6, err: "yaml: line 6: mapping values are not allowed in this context"},
+				{index: 2, line: 8, rootLine: 9, name: "b"},
+			},
+		},
+		{
+			name:  "document that isn't an object",
+			input: "- a\n- b\n",
+			expected: []decodedDocument{
+				{index: 0, line: 1, err: "error unmarshaling JSON: while decoding JSON: json: cannot unmarshal array into Go value of type kubernetes.Resource"},
+			},
+		},
+		{
+			name:  "JSON resource",
+			input: "\n{\n  \"kind\": \"ConfigMap\",\n  \"metadata\": {\"name\": \"a\"}\n}\n",
+			expected: []decodedDocument{
+				{index: 0, line: 2, rootLine: 2, name: "a"},
+			},
+		},
+		{
+			name:  "JSON lines",
+			input: "{\"kind\": \"ConfigMap\", \"metadata\": {\"name\": \"a\"}}\n{\"kind\": \"ConfigMap\", \"metadata\": {\"name\": \"b\"}}\n",
+			expected: []decodedDocument{
+				{index: 0, line: 1, rootLine: 1, name: "a"},
+				{index: 1, line: 2, rootLine: 2, name: "b"},
+			},
+		},
+		{
+			name:  "JSON array",
+			input: "[\n  {\"kind\": \"ConfigMap\", \"metadata\": {\"name\": \"a\"}},\n  {\"kind\": \"ConfigMap\", \"metadata\": {\"name\": \"b\"}},\n  \"c\"\n]\n{\"kind\": \"ConfigMap\", \"metadata\": {\"name\": \"d\"}}\n",
+			expected: []decodedDocument{
+				{index: 0, line: 2, rootLine: 2, name: "a"},
+				{index: 1, line: 3, rootLine: 3, name: "b"},
+				{index: 2, line: 4, err: "json: cannot unmarshal string into Go value of type kubernetes.Resource"},
+				{index: 3, line: 6, rootLine: 6, name: "d"},
+			},
+		},
+		{
+			name:  "JSON syntax error",
+			input: "{\"kind\": \"ConfigMap\", \"metadata\": {\"name\": \"a\"}}\n{\"kind\": ConfigMap}\n{\"kind\": \"ConfigMap\"}\n",
+			expected: []decodedDocument{
+				{index: 0, line: 1, rootLine: 1, name: "a"},
+				{index: 1, line: 2, err: "invalid character 'C' looking for beginning of value"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, decodeAll(t, test.input))
+		})
+	}
+}
+
+func TestYamlDecoderJsonPositions(t *testing.T) {
+	decoder := NewYamlDecoder(strings.NewReader("{\"kind\": \"ConfigMap\"}\n  {\"kind\": \"ConfigMap\",\n   \"data\": {\"key\": 1}}\n"))
+
+	_, err := decoder.Next()
+	assert.NoError(t, err)
+	document, err := decoder.Next()
+	assert.NoError(t, err)
+
+	// the root and the fields in the first line of the document are shifted to its column
+	assert.Equal(t, []int{2, 3}, []int{document.Node.Line, document.Node.Column})
+	assert.Equal(t, []int{2, 4}, []int{document.Node.Content[0].Line, document.Node.Content[0].Column})
+	assert.Equal(t, []int{3, 4}, []int{document.Node.Content[2].Line, document.Node.Content[2].Column})
+}
+
+type failingReader struct{}
+
+func (reader failingReader) Read(p []byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+func TestYamlDecoderReadError(t *testing.T) {
+	decoder := NewYamlDecoder(failingReader{})
+
+	_, err := decoder.Next()
+
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestParseResourcesFromYaml(t *testing.T) {
+	resources, err := ParseResourcesFromYaml(strings.NewReader("---\nkind: ConfigMap\nmetadata:\n  name: a\n---\n---\nkind: ConfigMap\nmetadata:\n  name: b\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Resource{
+		{"kind": "ConfigMap", "metadata": map[string]interface{}{"name": "a"}},
+		{"kind": "ConfigMap", "metadata": map[string]interface{}{"name": "b"}},
+	}, resources)
+
+	_, err = ParseResourcesFromYaml(strings.NewReader("a: 1\n---\nthis\n is:\n    wrong\n"))
+
+	assert.EqualError(t, err, "Error parsing resource from document 1: yaml: line 4: mapping values are not allowed in this context")
+}
+
+func TestYamlDecoderHeadComments(t *testing.T) {
+	input := "# first\n\n#second\nkind: ConfigMap # inline\n# not a head comment\n--- # marker\n  # indented\nkind: ConfigMap\n---\nkind: ConfigMap\n"
+	decoder := NewYamlDecoder(strings.NewReader(input))
+
+	expected := [][]string{{"first", "second"}, {"marker", "indented"}, {}}
+	for _, comments := range expected {
+		document, err := decoder.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, comments, document.HeadComments)
+	}
+}
@@ -0,0 +1,144 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"io"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// jsonDecoder decodes a stream of JSON values: a single resource, a sequence of resources (ie: JSON lines)
+// or arrays of resources, whose items are decoded as separate documents
+type jsonDecoder struct {
+	decoder *json.Decoder
+	lines   *lineCounter
+	index   int
+	// pending are the documents (or errors) of the items of the last array that haven't been returned yet
+	pending []decodedValue
+	err     error
+}
+
+type decodedValue struct {
+	document *YamlDocument
+	err      error
+}
+
+func newJsonDecoder(reader io.Reader) *jsonDecoder {
+	lines := &lineCounter{reader: reader}
+	return &jsonDecoder{
+		decoder: json.NewDecoder(lines),
+		lines:   lines,
+	}
+}
+
+// next returns the next resource of the stream. As the JSON decoder can't go on after a syntax error, the
+// *DocumentError of a syntax error is the last document of the stream.
+func (decoder *jsonDecoder) next() (*YamlDocument, error) {
+	if len(decoder.pending) > 0 {
+		value := decoder.pending[0]
+		decoder.pending = decoder.pending[1:]
+		return value.document, value.err
+	}
+	if decoder.err != nil {
+		return nil, decoder.err
+	}
+
+	var raw json.RawMessage
+	err := decoder.decoder.Decode(&raw)
+	if decoder.lines.err != nil {
+		decoder.err = decoder.lines.err
+		return nil, decoder.err
+	}
+	if err == io.EOF {
+		decoder.err = io.EOF
+		return nil, io.EOF
+	}
+	if err != nil {
+		decoder.err = io.EOF
+		documentError := &DocumentError{Index: decoder.index, Err: err}
+		if syntaxError, ok := err.(*json.SyntaxError); ok {
+			documentError.Line, _ = decoder.lines.position(syntaxError.Offset)
+		}
+		return nil, documentError
+	}
+
+	line, column := decoder.lines.position(decoder.decoder.InputOffset() - int64(len(raw)))
+	// JSON is YAML, so the positions of the fields can be found with the nodes of yaml.v3. They are only informative,
+	// the resource is decoded even if they can't be found.
+	var root *yamlv3.Node
+	node := &yamlv3.Node{}
+	if yamlv3.Unmarshal(raw, node) == nil && len(node.Content) > 0 {
+		root = node.Content[0]
+		shiftPosition(root, line, column, make(map[*yamlv3.Node]bool))
+	}
+	var items []json.RawMessage
+	if raw[0] != '[' || json.Unmarshal(raw, &items) != nil {
+		return decoder.decode(raw, root, line)
+	}
+
+	for i, item := range items {
+		var itemNode *yamlv3.Node
+		itemLine := line
+		if root != nil && i < len(root.Content) {
+			itemNode = root.Content[i]
+			itemLine = itemNode.Line
+		}
+		document, err := decoder.decode(item, itemNode, itemLine)
+		decoder.pending = append(decoder.pending, decodedValue{document: document, err: err})
+	}
+	return decoder.next()
+}
+
+// decode decodes a single resource, given the node with its position in the stream (nil if unknown)
+func (decoder *jsonDecoder) decode(raw json.RawMessage, node *yamlv3.Node, line int) (*YamlDocument, error) {
+	index := decoder.index
+	decoder.index++
+	document := &YamlDocument{
+		Index:    index,
+		Line:     line,
+		Node:     node,
+		Resource: Resource{},
+	}
+	err := json.Unmarshal(raw, &document.Resource)
+	if err != nil {
+		return nil, &DocumentError{Index: index, Line: line, Err: err}
+	}
+	return document, nil
+}
+
+// lineCounter keeps track of the lines of a stream as it's read, to find the line and column of its offsets
+type lineCounter struct {
+	reader io.Reader
+	read   int64
+	// newlines are the offsets of the newlines read after the last offset looked up
+	newlines []int64
+	// line is the number of lines before the last offset looked up, and lineStart the offset where its line starts
+	line      int
+	lineStart int64
+	// err is the first error found when reading the stream, other than io.EOF
+	err error
+}
+
+func (counter *lineCounter) Read(p []byte) (int, error) {
+	n, err := counter.reader.Read(p)
+	for i := 0; i < n; i++ {
+		if p[i] == '\n' {
+			counter.newlines = append(counter.newlines, counter.read+int64(i))
+		}
+	}
+	counter.read += int64(n)
+	if err != nil && err != io.EOF && counter.err == nil {
+		counter.err = err
+	}
+	return n, err
+}
+
+// position returns the line and column (starting at 1) of an offset of the stream. Offsets must be looked up in increasing order.
+func (counter *lineCounter) position(offset int64) (int, int) {
+	for len(counter.newlines) > 0 && counter.newlines[0] < offset {
+		counter.line++
+		counter.lineStart = counter.newlines[0] + 1
+		counter.newlines = counter.newlines[1:]
+	}
+	return counter.line + 1, int(offset-counter.lineStart) + 1
+}
@@ -1,11 +1,11 @@
 package kubernetes
 
 import (
-	"bytes"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/fllaca/scheriff/pkg/utils"
-	"sigs.k8s.io/yaml"
 )
 
 type Resource map[string]interface{}
@@ -35,24 +35,85 @@ func GetNamespace(resource map[string]interface{}) string {
 	return GetString(metadata, "namespace")
 }
 
+// GetAnnotation returns the value of an annotation of the resource, and whether it's set
+func GetAnnotation(resource map[string]interface{}, key string) (string, bool) {
+	annotations, _ := GetMetadata(resource)["annotations"].(map[string]interface{})
+	value, ok := annotations[key].(string)
+	return value, ok
+}
+
 func GetString(input map[string]interface{}, key string) string {
 	value, _ := input[key].(string)
 	return value
 }
 
-func ParseResourcesFromYaml(fileBytes []byte) ([]Resource, error) {
-	result := make([]Resource, 0)
-	documentsBytes := bytes.Split(fileBytes, []byte("\n---\n"))
+// GetField returns the value of the nested field of the given keys, or nil if any of them isn't found
+func GetField(input interface{}, keys ...string) interface{} {
+	value := input
+	for _, key := range keys {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
 
-	for docIndex, documentBytes := range documentsBytes {
-		resource := make(Resource)
-		err := yaml.Unmarshal(documentBytes, &resource)
+// ParseResourcesFromYaml reads the (non empty) resources of a stream of YAML documents
+func ParseResourcesFromYaml(reader io.Reader) ([]Resource, error) {
+	result := make([]Resource, 0)
+	decoder := NewYamlDecoder(reader)
+	for {
+		document, err := decoder.Next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if documentError, ok := err.(*DocumentError); ok {
+			return nil, fmt.Errorf("Error parsing resource from document %d: %s", documentError.Index, documentError)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("Error parsing resource from document %d: %s", docIndex, err)
+			return nil, err
+		}
+		if len(document.Resource) > 0 {
+			result = append(result, document.Resource)
+		}
+	}
+}
+
+// ListItems returns the items of a List, either a generic one (kind: List) or a typed one (ie: kind: ConfigMapList), and false
+// if the resource isn't a list. The items of typed lists get the apiVersion and kind of the list (without the "List" suffix) when they don't set them.
+// As other kinds may end in "List" too (ie: a custom resource IPAllowList), 'knownKind' tells which kinds (by "group/version/kind")
+// have a schema: typed lists whose kind has a schema are only lists when the kind of their items has a schema too (as the
+// lists of the Kubernetes schemas). When 'knownKind' is nil, all the typed lists are lists.
+func ListItems(resource map[string]interface{}, knownKind func(apiVersionKind string) bool) ([]interface{}, bool) {
+	kind := GetString(resource, "kind")
+	items, ok := resource["items"].([]interface{})
+	if !ok || !strings.HasSuffix(kind, "List") {
+		return nil, false
+	}
+	if kind == "List" {
+		return items, true
+	}
+	apiVersion := GetString(resource, "apiVersion")
+	itemKind := strings.TrimSuffix(kind, "List")
+	if knownKind != nil && knownKind(GetApiVersionKind(resource)) && !knownKind(utils.JoinNotEmptyStrings("/", apiVersion, itemKind)) {
+		return nil, false
+	}
+	typedItems := make([]interface{}, len(items))
+	for i, item := range items {
+		itemResource, ok := item.(map[string]interface{})
+		if !ok {
+			typedItems[i] = item
+			continue
 		}
-		if len(resource) > 0 {
-			result = append(result, resource)
+		typedItem := make(map[string]interface{}, len(itemResource)+2)
+		typedItem["apiVersion"] = apiVersion
+		typedItem["kind"] = itemKind
+		for key, value := range itemResource {
+			typedItem[key] = value
 		}
+		typedItems[i] = typedItem
 	}
-	return result, nil
+	return typedItems, true
 }
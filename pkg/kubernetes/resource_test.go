@@ -0,0 +1,89 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListItems(t *testing.T) {
+	tests := []struct {
+		name       string
+		resource   map[string]interface{}
+		knownKinds []string
+		expected   []interface{}
+		isList     bool
+	}{
+		{
+			name:     "not a list",
+			resource: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"},
+		},
+		{
+			name:     "list kind without items",
+			resource: map[string]interface{}{"apiVersion": "example.io/v1", "kind": "WaitList", "spec": map[string]interface{}{}},
+		},
+		{
+			name: "generic list",
+			resource: map[string]interface{}{"apiVersion": "v1", "kind": "List", "items": []interface{}{
+				map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"},
+				map[string]interface{}{"kind": "Secret"},
+			}},
+			expected: []interface{}{
+				map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"},
+				map[string]interface{}{"kind": "Secret"},
+			},
+			isList: true,
+		},
+		{
+			name: "typed list",
+			resource: map[string]interface{}{"apiVersion": "apps/v1", "kind": "DeploymentList", "items": []interface{}{
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "a"}},
+				map[string]interface{}{"apiVersion": "apps/v1beta2", "kind": "Deployment"},
+				"not an object",
+			}},
+			expected: []interface{}{
+				map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "a"}},
+				map[string]interface{}{"apiVersion": "apps/v1beta2", "kind": "Deployment"},
+				"not an object",
+			},
+			isList: true,
+		},
+		{
+			name: "typed list of the schemas",
+			resource: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMapList", "items": []interface{}{
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "a"}},
+			}},
+			knownKinds: []string{"v1/ConfigMap", "v1/ConfigMapList"},
+			expected: []interface{}{
+				map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "a"}},
+			},
+			isList: true,
+		},
+		{
+			name: "custom resource whose kind ends in List",
+			resource: map[string]interface{}{"apiVersion": "example.io/v1", "kind": "IPAllowList", "items": []interface{}{
+				map[string]interface{}{"cidr": "10.0.0.0/8"},
+			}},
+			knownKinds: []string{"example.io/v1/IPAllowList"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var knownKind func(string) bool
+			if test.knownKinds != nil {
+				knownKind = func(apiVersionKind string) bool {
+					for _, kind := range test.knownKinds {
+						if kind == apiVersionKind {
+							return true
+						}
+					}
+					return false
+				}
+			}
+			items, isList := ListItems(test.resource, knownKind)
+			assert.Equal(t, test.isList, isList)
+			assert.Equal(t, test.expected, items)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fllaca/scheriff/pkg/validate"
+)
+
+// JSONReporter collects all the validation results and outputs them as a single JSON document when flushed.
+// Suppressed findings are listed apart from the other results.
+type JSONReporter struct {
+	out        io.Writer
+	errOut     io.Writer
+	results    []validate.ValidationResult
+	suppressed []validate.ValidationResult
+}
+
+type jsonDocument struct {
+	Results    []validate.ValidationResult `json:"results"`
+	Suppressed []validate.ValidationResult `json:"suppressed,omitempty"`
+	Summary    Summary                     `json:"summary"`
+}
+
+func NewJSONReporter(out io.Writer, errOut io.Writer) *JSONReporter {
+	return &JSONReporter{
+		out:     out,
+		errOut:  errOut,
+		results: make([]validate.ValidationResult, 0),
+	}
+}
+
+// Logf writes to 'errOut' so that 'out' only contains the JSON document
+func (jsonReporter *JSONReporter) Logf(format string, args ...interface{}) {
+	fmt.Fprintf(jsonReporter.errOut, format, args...)
+}
+
+func (jsonReporter *JSONReporter) Report(source string, results []validate.ValidationResult) {
+	for _, result := range results {
+		if result.Severity == validate.SeveritySuppressed {
+			jsonReporter.suppressed = append(jsonReporter.suppressed, result)
+			continue
+		}
+		jsonReporter.results = append(jsonReporter.results, result)
+	}
+}
+
+func (jsonReporter *JSONReporter) Flush(summary Summary) error {
+	encoder := json.NewEncoder(jsonReporter.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jsonDocument{
+		Results:    jsonReporter.results,
+		Suppressed: jsonReporter.suppressed,
+		Summary:    summary,
+	})
+}
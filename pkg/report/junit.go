@@ -0,0 +1,152 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/fllaca/scheriff/pkg/utils"
+	"github.com/fllaca/scheriff/pkg/validate"
+)
+
+// JUnitReporter outputs the validation results as a JUnit XML report: each source becomes a testsuite and each validation result a testcase.
+// Errors are reported as failures, while warnings are reported as skipped testcases, or as failures in strict mode.
+// Suppressed findings are always reported as skipped testcases.
+type JUnitReporter struct {
+	out    io.Writer
+	errOut io.Writer
+	strict bool
+	suites []junitTestSuite
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+func NewJUnitReporter(out io.Writer, errOut io.Writer, strict bool) *JUnitReporter {
+	return &JUnitReporter{
+		out:    out,
+		errOut: errOut,
+		strict: strict,
+		suites: make([]junitTestSuite, 0),
+	}
+}
+
+// Logf writes to 'errOut' so that 'out' only contains the XML report
+func (junitReporter *JUnitReporter) Logf(format string, args ...interface{}) {
+	fmt.Fprintf(junitReporter.errOut, format, args...)
+}
+
+// Report adds the results to the testsuite of 'source', which is created the first time the source is reported
+// (the findings across files are reported after the results of each file)
+func (junitReporter *JUnitReporter) Report(source string, results []validate.ValidationResult) {
+	suite := junitReporter.suite(source)
+	for _, result := range results {
+		testCase := junitTestCase{
+			Name:      testCaseName(result),
+			ClassName: source,
+		}
+		switch {
+		case result.Severity == validate.SeverityError, result.Severity == validate.SeverityWarning && junitReporter.strict:
+			testCase.Failure = &junitFailure{
+				Message: result.Message,
+				Type:    string(result.Severity),
+				Content: result.Message,
+			}
+			suite.Failures++
+		case result.Severity == validate.SeverityWarning:
+			testCase.Skipped = &junitSkipped{
+				Message: result.Message,
+			}
+			suite.Skipped++
+		case result.Severity == validate.SeveritySuppressed:
+			testCase.Skipped = &junitSkipped{
+				Message: fmt.Sprintf("Suppressed: %s", result.Message),
+			}
+			suite.Skipped++
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Tests = len(suite.TestCases)
+}
+
+// suite returns the testsuite of 'source', adding it if it doesn't exist yet
+func (junitReporter *JUnitReporter) suite(source string) *junitTestSuite {
+	for i := range junitReporter.suites {
+		if junitReporter.suites[i].Name == source {
+			return &junitReporter.suites[i]
+		}
+	}
+	junitReporter.suites = append(junitReporter.suites, junitTestSuite{
+		Name:      source,
+		TestCases: make([]junitTestCase, 0),
+	})
+	return &junitReporter.suites[len(junitReporter.suites)-1]
+}
+
+func (junitReporter *JUnitReporter) Flush(summary Summary) error {
+	report := junitTestSuites{
+		Name:   "scheriff",
+		Suites: junitReporter.suites,
+	}
+	for _, suite := range junitReporter.suites {
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+	}
+	_, err := io.WriteString(junitReporter.out, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(junitReporter.out)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(junitReporter.out)
+	return err
+}
+
+// testCaseName identifies the validated resource, or the document when the resource couldn't be parsed
+// prefixed by the schema when validating against several ones
+func testCaseName(result validate.ValidationResult) string {
+	name := fmt.Sprintf("%s (%s)", utils.JoinNotEmptyStrings("/", result.Namespace, result.Name), result.Kind)
+	if result.Kind == "" {
+		name = fmt.Sprintf("document %d", result.Document)
+	}
+	if result.Schema != "" {
+		return fmt.Sprintf("[%s] %s", result.Schema, name)
+	}
+	return name
+}
@@ -0,0 +1,112 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fllaca/scheriff/pkg/validate"
+)
+
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+	FormatSARIF = "sarif"
+)
+
+// Formats lists the output formats supported by NewReporter
+var Formats = []string{FormatText, FormatJSON, FormatJUnit, FormatSARIF}
+
+// Reporter outputs the results of a validation run
+type Reporter interface {
+	// Logf outputs informative messages about the progress of the validation
+	Logf(format string, args ...interface{})
+	// Report outputs the validation results of a single source (a file or stdin)
+	Report(source string, results []validate.ValidationResult)
+	// Flush outputs any pending results along with the summary of the whole validation run
+	Flush(summary Summary) error
+}
+
+// Summary holds the aggregated figures of a validation run
+type Summary struct {
+	Total    int `json:"total"`
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Valid    int `json:"valid"`
+	// Suppressed counts the errors and warnings suppressed by annotations or comments
+	Suppressed int `json:"suppressed"`
+	ExitCode   int `json:"exitCode"`
+	// Schemas holds the figures of each schema when validating against several ones
+	Schemas []SchemaSummary `json:"schemas,omitempty"`
+}
+
+// SchemaSummary holds the aggregated figures of the results of a single schema
+type SchemaSummary struct {
+	Schema     string `json:"schema"`
+	Total      int    `json:"total"`
+	Errors     int    `json:"errors"`
+	Warnings   int    `json:"warnings"`
+	Valid      int    `json:"valid"`
+	Suppressed int    `json:"suppressed"`
+}
+
+// Summarize counts the validation results by severity, in total and per schema
+func Summarize(results []validate.ValidationResult, exitCode int) Summary {
+	summary := Summary{
+		Total:    len(results),
+		ExitCode: exitCode,
+	}
+	summary.Errors, summary.Warnings, summary.Valid, summary.Suppressed = countSeverities(results)
+
+	schemaResults := make(map[string][]validate.ValidationResult)
+	for _, result := range results {
+		if result.Schema == "" {
+			continue
+		}
+		if _, ok := schemaResults[result.Schema]; !ok {
+			summary.Schemas = append(summary.Schemas, SchemaSummary{Schema: result.Schema})
+		}
+		schemaResults[result.Schema] = append(schemaResults[result.Schema], result)
+	}
+	for i := range summary.Schemas {
+		schemaSummary := &summary.Schemas[i]
+		results := schemaResults[schemaSummary.Schema]
+		schemaSummary.Total = len(results)
+		schemaSummary.Errors, schemaSummary.Warnings, schemaSummary.Valid, schemaSummary.Suppressed = countSeverities(results)
+	}
+	return summary
+}
+
+func countSeverities(results []validate.ValidationResult) (errors int, warnings int, valid int, suppressed int) {
+	for _, result := range results {
+		switch result.Severity {
+		case validate.SeverityError:
+			errors++
+		case validate.SeverityWarning:
+			warnings++
+		case validate.SeverityOK:
+			valid++
+		case validate.SeveritySuppressed:
+			suppressed++
+		}
+	}
+	return errors, warnings, valid, suppressed
+}
+
+// NewReporter returns the Reporter for the given output format.
+// Results are written to 'out', while 'errOut' receives the log messages of formats that are meant to be machine-readable.
+// When 'strict' is set, formats that classify the results as passed or failed will consider warnings as failures.
+func NewReporter(format string, strict bool, out io.Writer, errOut io.Writer) (Reporter, error) {
+	switch format {
+	case FormatText, "":
+		return NewTextReporter(out), nil
+	case FormatJSON:
+		return NewJSONReporter(out, errOut), nil
+	case FormatJUnit:
+		return NewJUnitReporter(out, errOut, strict), nil
+	case FormatSARIF:
+		return NewSARIFReporter(out, errOut), nil
+	default:
+		return nil, fmt.Errorf("Unknown output format '%s'", format)
+	}
+}
@@ -0,0 +1,230 @@
+package report_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fllaca/scheriff/pkg/report"
+	"github.com/fllaca/scheriff/pkg/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	results := []validate.ValidationResult{
+		{Message: "valid", Severity: validate.SeverityOK},
+		{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning},
+		{Message: "Error at \"/spec/secretName\":Property 'secretName' is missing", Severity: validate.SeverityError},
+		{Message: "valid", Severity: validate.SeverityOK},
+		{Message: "Property 'foo' is unsupported", Severity: validate.SeveritySuppressed},
+	}
+
+	assert.Equal(t, report.Summary{Total: 5, Errors: 1, Warnings: 1, Valid: 2, Suppressed: 1, ExitCode: 1}, report.Summarize(results, 1))
+}
+
+func TestSummarizeSchemas(t *testing.T) {
+	results := []validate.ValidationResult{
+		{Message: "Property 'immutable' is unsupported", Severity: validate.SeverityError, Schema: "1.17"},
+		{Message: "valid", Severity: validate.SeverityOK, Schema: "1.17"},
+		{Message: "valid", Severity: validate.SeverityOK, Schema: "1.18"},
+		{Message: "valid", Severity: validate.SeverityOK, Schema: "1.18"},
+	}
+
+	assert.Equal(t, report.Summary{
+		Total: 4, Errors: 1, Warnings: 0, Valid: 3, ExitCode: 1,
+		Schemas: []report.SchemaSummary{
+			{Schema: "1.17", Total: 2, Errors: 1, Valid: 1},
+			{Schema: "1.18", Total: 2, Valid: 2},
+		},
+	}, report.Summarize(results, 1))
+}
+
+func TestJSONReporter(t *testing.T) {
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	reporter := report.NewJSONReporter(out, errOut)
+
+	reporter.Logf("Validating %s\n", "test.yaml")
+	reporter.Report("test.yaml", []validate.ValidationResult{
+		{Message: "valid", Severity: validate.SeverityOK, Name: "test-cm", Namespace: "default", Kind: "v1/ConfigMap", Source: "test.yaml", Document: 1},
+		{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeveritySuppressed, Rule: validate.RuleUnknownKind, Name: "unknown", Kind: "example.io/v1/UnknownCRD", Source: "test.yaml", Document: 2},
+	})
+	err := reporter.Flush(report.Summary{Total: 2, Valid: 1, Suppressed: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Validating test.yaml\n", errOut.String())
+	assert.JSONEq(t, `{
+		"results": [
+			{"message": "valid", "severity": "OK", "name": "test-cm", "namespace": "default", "kind": "v1/ConfigMap", "source": "test.yaml", "document": 1}
+		],
+		"suppressed": [
+			{"message": "Kind 'example.io/v1/UnknownCRD' not found in schema", "severity": "SUPPRESSED", "rule": "unknown-kind", "name": "unknown", "kind": "example.io/v1/UnknownCRD", "source": "test.yaml", "document": 2}
+		],
+		"summary": {"total": 2, "errors": 0, "warnings": 0, "valid": 1, "suppressed": 1, "exitCode": 0}
+	}`, out.String())
+}
+
+func TestNewReporterUnknownFormat(t *testing.T) {
+	_, err := report.NewReporter("yaml", false, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.EqualError(t, err, "Unknown output format 'yaml'")
+}
+
+func TestJUnitReporter(t *testing.T) {
+	results := []validate.ValidationResult{
+		{Message: "valid", Severity: validate.SeverityOK, Name: "test-cm", Namespace: "default", Kind: "v1/ConfigMap", Source: "test.yaml", Document: 0},
+		{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning, Name: "unknown", Kind: "example.io/v1/UnknownCRD", Source: "test.yaml", Document: 1},
+		{Message: "Error parsing k8s resource from document 2", Severity: validate.SeverityError, Source: "test.yaml", Document: 2},
+	}
+	tests := []struct {
+		name     string
+		strict   bool
+		expected string
+	}{
+		{
+			name:   "warnings as skipped",
+			strict: false,
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="scheriff" tests="3" failures="1" skipped="1">
+  <testsuite name="test.yaml" tests="3" failures="1" skipped="1">
+    <testcase name="default/test-cm (v1/ConfigMap)" classname="test.yaml"></testcase>
+    <testcase name="unknown (example.io/v1/UnknownCRD)" classname="test.yaml">
+      <skipped message="Kind &#39;example.io/v1/UnknownCRD&#39; not found in schema"></skipped>
+    </testcase>
+    <testcase name="document 2" classname="test.yaml">
+      <failure message="Error parsing k8s resource from document 2" type="ERROR">Error parsing k8s resource from document 2</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+		},
+		{
+			name:   "warnings as failures in strict mode",
+			strict: true,
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="scheriff" tests="3" failures="2" skipped="0">
+  <testsuite name="test.yaml" tests="3" failures="2" skipped="0">
+    <testcase name="default/test-cm (v1/ConfigMap)" classname="test.yaml"></testcase>
+    <testcase name="unknown (example.io/v1/UnknownCRD)" classname="test.yaml">
+      <failure message="Kind &#39;example.io/v1/UnknownCRD&#39; not found in schema" type="WARN">Kind &#39;example.io/v1/UnknownCRD&#39; not found in schema</failure>
+    </testcase>
+    <testcase name="document 2" classname="test.yaml">
+      <failure message="Error parsing k8s resource from document 2" type="ERROR">Error parsing k8s resource from document 2</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			reporter := report.NewJUnitReporter(out, &bytes.Buffer{}, test.strict)
+			reporter.Report("test.yaml", results)
+			err := reporter.Flush(report.Summarize(results, 1))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
+
+func TestJUnitReporterSameSource(t *testing.T) {
+	out := &bytes.Buffer{}
+	reporter := report.NewJUnitReporter(out, &bytes.Buffer{}, false)
+	reporter.Report("a.yaml", []validate.ValidationResult{
+		{Message: "valid", Severity: validate.SeverityOK, Name: "app", Kind: "apps/v1/Deployment", Source: "a.yaml", Document: 0},
+	})
+	reporter.Report("b.yaml", []validate.ValidationResult{
+		{Message: "valid", Severity: validate.SeverityOK, Name: "app", Kind: "v1/Service", Source: "b.yaml", Document: 0},
+	})
+	reporter.Report("a.yaml", []validate.ValidationResult{
+		{Message: "Secret 'app' not found in the validated resources", Severity: validate.SeverityError, Rule: validate.RuleMissingReference, Name: "app", Kind: "apps/v1/Deployment", Source: "a.yaml", Document: 0},
+	})
+	err := reporter.Flush(report.Summary{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="scheriff" tests="3" failures="1" skipped="0">
+  <testsuite name="a.yaml" tests="2" failures="1" skipped="0">
+    <testcase name="app (apps/v1/Deployment)" classname="a.yaml"></testcase>
+    <testcase name="app (apps/v1/Deployment)" classname="a.yaml">
+      <failure message="Secret &#39;app&#39; not found in the validated resources" type="ERROR">Secret &#39;app&#39; not found in the validated resources</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="b.yaml" tests="1" failures="0" skipped="0">
+    <testcase name="app (v1/Service)" classname="b.yaml"></testcase>
+  </testsuite>
+</testsuites>
+`, out.String())
+}
+
+func TestSARIFReporter(t *testing.T) {
+	out := &bytes.Buffer{}
+	reporter := report.NewSARIFReporter(out, &bytes.Buffer{})
+
+	reporter.Report("manifests/test.yaml", []validate.ValidationResult{
+		{Message: "valid", Severity: validate.SeverityOK, Name: "test-cm", Kind: "v1/ConfigMap", Source: "manifests/test.yaml", Document: 0},
+		{Message: "Error at \"/spec/secretName\":Property 'secretName' is missing", Severity: validate.SeverityError, Rule: validate.RuleSchemaViolation, Name: "cert", Kind: "cert-manager.io/v1alpha2/Certificate", Source: "manifests/test.yaml", Document: 1, Path: "/spec/secretName", Line: 9, Column: 1},
+	})
+	reporter.Report(validate.StdinSource, []validate.ValidationResult{
+		{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeverityWarning, Rule: validate.RuleUnknownKind, Name: "unknown", Kind: "example.io/v1/UnknownCRD", Source: validate.StdinSource, Document: 0},
+		{Message: "Property 'foo' is unsupported", Severity: validate.SeveritySuppressed, Rule: validate.RuleSchemaViolation, Name: "ignored", Kind: "v1/ConfigMap", Source: validate.StdinSource, Document: 1},
+	})
+	err := reporter.Flush(report.Summary{})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": [{
+			"tool": {
+				"driver": {
+					"name": "scheriff",
+					"informationUri": "https://github.com/fllaca/scheriff",
+					"rules": [
+						{"id": "schema-violation", "shortDescription": {"text": "The resource doesn't match the schema of its kind"}},
+						{"id": "unknown-kind", "shortDescription": {"text": "The kind of the resource is not defined in the schemas"}}
+					]
+				}
+			},
+			"results": [
+				{
+					"ruleId": "schema-violation",
+					"level": "error",
+					"message": {"text": "Error at \"/spec/secretName\":Property 'secretName' is missing"},
+					"locations": [{"physicalLocation": {"artifactLocation": {"uri": "manifests/test.yaml"}, "region": {"startLine": 9, "startColumn": 1}}}]
+				},
+				{
+					"ruleId": "unknown-kind",
+					"level": "warning",
+					"message": {"text": "Kind 'example.io/v1/UnknownCRD' not found in schema"}
+				},
+				{
+					"ruleId": "schema-violation",
+					"level": "note",
+					"message": {"text": "Property 'foo' is unsupported"},
+					"suppressions": [{"kind": "inSource"}]
+				}
+			]
+		}]
+	}`, out.String())
+}
+
+func TestTextReporterSuppressed(t *testing.T) {
+	out := &bytes.Buffer{}
+	reporter := report.NewTextReporter(out)
+
+	results := []validate.ValidationResult{
+		{Message: "valid", Severity: validate.SeverityOK, Name: "test-cm", Namespace: "default", Kind: "v1/ConfigMap", Source: "test.yaml", Document: 0},
+		{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: validate.SeveritySuppressed, Rule: validate.RuleUnknownKind, Name: "unknown", Kind: "example.io/v1/UnknownCRD", Source: "test.yaml", Document: 1, Line: 8, Column: 1},
+	}
+	reporter.Report("test.yaml", results)
+	err := reporter.Flush(report.Summarize(results, 0))
+
+	assert.NoError(t, err)
+	assert.NotContains(t, out.String(), "SUPPRESSED")
+	assert.Contains(t, out.String(), "Validating manifests in test.yaml:\n\t - ")
+	assert.True(t, strings.HasSuffix(out.String(), "): valid\n\n"+
+		"Suppressed findings:\n"+
+		"\t - test.yaml: unknown (example.io/v1/UnknownCRD) at line 8, column 1: [unknown-kind] Kind 'example.io/v1/UnknownCRD' not found in schema\n\n"), out.String())
+}
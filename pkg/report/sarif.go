@@ -0,0 +1,196 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/fllaca/scheriff/pkg/validate"
+)
+
+const (
+	sarifVersion   = "2.1.0"
+	sarifSchema    = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolName  = "scheriff"
+	sarifToolURI   = "https://github.com/fllaca/scheriff"
+	sarifLevelErr  = "error"
+	sarifLevelWarn = "warning"
+	sarifLevelNote = "note"
+	// sarifSuppressionInSource is the kind of the suppressions declared in the analyzed files
+	sarifSuppressionInSource = "inSource"
+)
+
+// SARIFReporter outputs the ERROR and WARN validation results as a SARIF 2.1.0 log, so they can be consumed by code scanning tools.
+// Suppressed findings are included as notes with an "inSource" suppression.
+type SARIFReporter struct {
+	out     io.Writer
+	errOut  io.Writer
+	results []sarifResult
+	rules   map[string]bool
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+	Properties   *sarifProperties   `json:"properties,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+type sarifProperties struct {
+	Schema string `json:"schema"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+func NewSARIFReporter(out io.Writer, errOut io.Writer) *SARIFReporter {
+	return &SARIFReporter{
+		out:     out,
+		errOut:  errOut,
+		results: make([]sarifResult, 0),
+		rules:   make(map[string]bool),
+	}
+}
+
+// Logf writes to 'errOut' so that 'out' only contains the SARIF log
+func (sarifReporter *SARIFReporter) Logf(format string, args ...interface{}) {
+	fmt.Fprintf(sarifReporter.errOut, format, args...)
+}
+
+func (sarifReporter *SARIFReporter) Report(source string, results []validate.ValidationResult) {
+	for _, result := range results {
+		level := sarifLevel(result.Severity)
+		if level == "" {
+			continue
+		}
+		sarifResult := sarifResult{
+			RuleID:  result.Rule,
+			Level:   level,
+			Message: sarifMessage{Text: result.Message},
+		}
+		if source != validate.StdinSource {
+			physicalLocation := sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(source)},
+			}
+			if result.Line > 0 {
+				physicalLocation.Region = &sarifRegion{
+					StartLine:   result.Line,
+					StartColumn: result.Column,
+				}
+			}
+			sarifResult.Locations = []sarifLocation{{PhysicalLocation: physicalLocation}}
+		}
+		if result.Severity == validate.SeveritySuppressed {
+			sarifResult.Suppressions = []sarifSuppression{{Kind: sarifSuppressionInSource}}
+		}
+		if result.Schema != "" {
+			sarifResult.Properties = &sarifProperties{Schema: result.Schema}
+		}
+		sarifReporter.rules[result.Rule] = true
+		sarifReporter.results = append(sarifReporter.results, sarifResult)
+	}
+}
+
+func (sarifReporter *SARIFReporter) Flush(summary Summary) error {
+	encoder := json.NewEncoder(sarifReporter.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           sarifToolName,
+						InformationURI: sarifToolURI,
+						Rules:          sarifReporter.reportedRules(),
+					},
+				},
+				Results: sarifReporter.results,
+			},
+		},
+	})
+}
+
+// reportedRules returns the descriptors of the rules found in the results, sorted by id
+func (sarifReporter *SARIFReporter) reportedRules() []sarifRule {
+	ids := make([]string, 0, len(sarifReporter.rules))
+	for id := range sarifReporter.rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	rules := make([]sarifRule, 0, len(ids))
+	for _, id := range ids {
+		rules = append(rules, sarifRule{
+			ID:               id,
+			ShortDescription: sarifMessage{Text: validate.RuleDescriptions[id]},
+		})
+	}
+	return rules
+}
+
+func sarifLevel(severity validate.Severity) string {
+	switch severity {
+	case validate.SeverityError:
+		return sarifLevelErr
+	case validate.SeverityWarning:
+		return sarifLevelWarn
+	case validate.SeveritySuppressed:
+		return sarifLevelNote
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,193 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fllaca/scheriff/pkg/utils"
+	"github.com/fllaca/scheriff/pkg/validate"
+	"github.com/gookit/color"
+)
+
+// TextReporter prints human readable (and colored) results as soon as they are reported.
+// Suppressed findings are printed apart, when flushed.
+// When validating against several schemas, it also prints a table with the result of each resource per schema.
+type TextReporter struct {
+	out        io.Writer
+	suppressed []suppressedResult
+	schemas    []string
+	rows       []*schemaTableRow
+	rowsMap    map[string]*schemaTableRow
+}
+
+// suppressedResult is a suppressed finding along with the source it was reported for
+type suppressedResult struct {
+	source string
+	result validate.ValidationResult
+}
+
+// schemaTableRow holds the most severe result of a resource for each schema
+type schemaTableRow struct {
+	resource   string
+	severities map[string]validate.Severity
+}
+
+func NewTextReporter(out io.Writer) *TextReporter {
+	return &TextReporter{
+		out:     out,
+		schemas: make([]string, 0),
+		rows:    make([]*schemaTableRow, 0),
+		rowsMap: make(map[string]*schemaTableRow),
+	}
+}
+
+func (textReporter *TextReporter) Logf(format string, args ...interface{}) {
+	fmt.Fprintf(textReporter.out, format, args...)
+}
+
+func (textReporter *TextReporter) Report(source string, results []validate.ValidationResult) {
+	if source != validate.StdinSource {
+		fmt.Fprintf(textReporter.out, "Validating manifests in %s:\n", source)
+	}
+	for _, result := range results {
+		textReporter.addToSchemaTable(source, result)
+		if result.Severity == validate.SeveritySuppressed {
+			textReporter.suppressed = append(textReporter.suppressed, suppressedResult{source: source, result: result})
+			continue
+		}
+		fmt.Fprintf(textReporter.out, "\t - %s%s, %s (%s)%s: %s\n", schemaPrefix(result), colorSeverity(result.Severity), utils.JoinNotEmptyStrings("/", result.Namespace, result.Name), result.Kind, location(result), result.Message)
+	}
+	fmt.Fprintln(textReporter.out)
+}
+
+func (textReporter *TextReporter) Flush(summary Summary) error {
+	if len(textReporter.suppressed) > 0 {
+		fmt.Fprintln(textReporter.out, "Suppressed findings:")
+		for _, suppressed := range textReporter.suppressed {
+			result := suppressed.result
+			fmt.Fprintf(textReporter.out, "\t - %s%s: %s (%s)%s: [%s] %s\n", schemaPrefix(result), suppressed.source, utils.JoinNotEmptyStrings("/", result.Namespace, result.Name), result.Kind, location(result), result.Rule, result.Message)
+		}
+		fmt.Fprintln(textReporter.out)
+	}
+	if len(textReporter.schemas) < 2 {
+		return nil
+	}
+	fmt.Fprintln(textReporter.out, "Results per schema:")
+	writer := tabwriter.NewWriter(textReporter.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(writer, "RESOURCE\t%s\n", strings.Join(textReporter.schemas, "\t"))
+	for _, row := range textReporter.rows {
+		severities := make([]string, 0, len(textReporter.schemas))
+		for _, schema := range textReporter.schemas {
+			severity, ok := row.severities[schema]
+			if !ok {
+				severity = "-"
+			}
+			severities = append(severities, string(severity))
+		}
+		fmt.Fprintf(writer, "%s\t%s\n", row.resource, strings.Join(severities, "\t"))
+	}
+	err := writer.Flush()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(textReporter.out)
+	return err
+}
+
+// addToSchemaTable keeps the most severe result of each resource per schema
+func (textReporter *TextReporter) addToSchemaTable(source string, result validate.ValidationResult) {
+	if result.Schema == "" {
+		return
+	}
+	if !containsString(textReporter.schemas, result.Schema) {
+		textReporter.schemas = append(textReporter.schemas, result.Schema)
+	}
+	resource := fmt.Sprintf("%s (%s)", utils.JoinNotEmptyStrings("/", result.Namespace, result.Name), result.Kind)
+	if result.Kind == "" {
+		resource = fmt.Sprintf("document %d", result.Document)
+	}
+	resource = fmt.Sprintf("%s: %s", source, resource)
+	key := fmt.Sprintf("%s#%d", resource, result.Document)
+	if result.Item != nil {
+		key = fmt.Sprintf("%s#%d", key, *result.Item)
+	}
+	row, ok := textReporter.rowsMap[key]
+	if !ok {
+		row = &schemaTableRow{
+			resource:   resource,
+			severities: make(map[string]validate.Severity),
+		}
+		textReporter.rowsMap[key] = row
+		textReporter.rows = append(textReporter.rows, row)
+	}
+	if severityRank(result.Severity) > severityRank(row.severities[result.Schema]) {
+		row.severities[result.Schema] = result.Severity
+	}
+}
+
+// severityRank orders severities from the least to the most severe
+func severityRank(severity validate.Severity) int {
+	switch severity {
+	case validate.SeveritySuppressed:
+		return 1
+	case validate.SeverityOK:
+		return 2
+	case validate.SeverityWarning:
+		return 3
+	case validate.SeverityError:
+		return 4
+	default:
+		return 0
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaPrefix identifies the schema of the result when validating against several ones
+func schemaPrefix(result validate.ValidationResult) string {
+	if result.Schema == "" {
+		return ""
+	}
+	return fmt.Sprintf("[%s] ", result.Schema)
+}
+
+// location describes the position of the result within its source (and its List, if any), if known
+func location(result validate.ValidationResult) string {
+	item := ""
+	if result.Item != nil {
+		item = fmt.Sprintf(" in list item %d", *result.Item)
+	}
+	switch {
+	case result.Line > 0 && result.Column > 0:
+		return fmt.Sprintf("%s at line %d, column %d", item, result.Line, result.Column)
+	case result.Line > 0:
+		return fmt.Sprintf("%s at line %d", item, result.Line)
+	default:
+		return item
+	}
+}
+
+func colorSeverity(severity validate.Severity) string {
+	red := color.FgRed.Render
+	green := color.FgGreen.Render
+	yellow := color.FgYellow.Render
+	switch severity {
+	case validate.SeverityError:
+		return red(severity)
+	case validate.SeverityWarning:
+		return yellow(severity)
+	case validate.SeverityOK:
+		return green(severity)
+	default:
+		return (string)(severity)
+	}
+}
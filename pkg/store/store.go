@@ -0,0 +1,167 @@
+package store
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fllaca/scheriff/pkg/fs"
+)
+
+const (
+	schemaFilename = "swagger.json"
+	bundleFilename = "bundle.gz"
+	crdsFolder     = "crds"
+)
+
+// Store keeps local copies of the Kubernetes schemas (and CustomResourceDefinitions) of each Kubernetes version,
+// organized as '<dir>/<version>/swagger.json' and '<dir>/<version>/crds/', along with the bundle compiled from them ('<dir>/<version>/bundle.gz')
+type Store struct {
+	dir string
+}
+
+// Version describes the files stored for a Kubernetes version
+type Version struct {
+	Version string
+	// Schema is the path of the OpenAPI schema, empty if only CRDs were imported
+	Schema string
+	// Crds are the paths of the CustomResourceDefinition files
+	Crds []string
+	// Bundle is the path of the schema bundle compiled from the schema and CRDs, if any
+	Bundle string
+}
+
+func New(dir string) *Store {
+	return &Store{
+		dir: dir,
+	}
+}
+
+// DefaultDir returns the default location of the store inside the user cache directory (ie: ~/.cache/scheriff/schemas)
+func DefaultDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(".scheriff", "schemas")
+	}
+	return filepath.Join(cacheDir, "scheriff", "schemas")
+}
+
+func (store *Store) Dir() string {
+	return store.dir
+}
+
+// Import copies a file into the store for the given Kubernetes version: YAML files are stored as CustomResourceDefinitions
+// and any other file as the OpenAPI schema of the version. It returns the path of the stored file.
+func (store *Store) Import(file string, version string) (string, error) {
+	version = normalizeVersion(version)
+	if version == "" {
+		return "", fmt.Errorf("Invalid empty Kubernetes version")
+	}
+	fileBytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	target := filepath.Join(store.dir, version, schemaFilename)
+	if fs.IsYamlFilter(file) {
+		target = filepath.Join(store.dir, version, crdsFolder, filepath.Base(file))
+	}
+	err = os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return "", err
+	}
+	// the bundle compiled from the previous files of the version is no longer valid
+	err = os.Remove(store.BundlePath(version))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	return target, ioutil.WriteFile(target, fileBytes, 0644)
+}
+
+// BundlePath returns the path of the schema bundle of a Kubernetes version
+func (store *Store) BundlePath(version string) string {
+	return filepath.Join(store.dir, normalizeVersion(version), bundleFilename)
+}
+
+// List returns the Kubernetes versions found in the store, sorted by version
+func (store *Store) List() ([]Version, error) {
+	files, err := ioutil.ReadDir(store.dir)
+	if os.IsNotExist(err) {
+		return []Version{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]Version, 0, len(files))
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+		version, err := store.Get(file.Name())
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i].Version, versions[j].Version) < 0
+	})
+	return versions, nil
+}
+
+// Get returns the files stored for a Kubernetes version
+func (store *Store) Get(version string) (Version, error) {
+	version = normalizeVersion(version)
+	versionDir := filepath.Join(store.dir, version)
+	if _, err := os.Stat(versionDir); os.IsNotExist(err) || version == "" {
+		return Version{}, fmt.Errorf("Kubernetes version '%s' not found in the schema store %s, use 'scheriff schema import <file> --version %s' to add it", version, store.dir, version)
+	}
+
+	storedVersion := Version{
+		Version: version,
+		Crds:    make([]string, 0),
+	}
+	schema := filepath.Join(versionDir, schemaFilename)
+	if _, err := os.Stat(schema); err == nil {
+		storedVersion.Schema = schema
+	}
+	if _, err := os.Stat(store.BundlePath(version)); err == nil {
+		storedVersion.Bundle = store.BundlePath(version)
+	}
+	crdsDir := filepath.Join(versionDir, crdsFolder)
+	if _, err := os.Stat(crdsDir); err == nil {
+		err = fs.ApplyToPathWithFilter(crdsDir, false, func(file string) error {
+			storedVersion.Crds = append(storedVersion.Crds, file)
+			return nil
+		}, fs.IsYamlFilter)
+		if err != nil {
+			return Version{}, err
+		}
+	}
+	return storedVersion, nil
+}
+
+// normalizeVersion removes the "v" prefix of versions like "v1.24.3"
+func normalizeVersion(version string) string {
+	return strings.TrimPrefix(strings.TrimSpace(version), "v")
+}
+
+// compareVersions compares dot-separated versions numerically when possible (so that 1.9 < 1.10)
+func compareVersions(a string, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNumber, aErr := strconv.Atoi(aParts[i])
+		bNumber, bErr := strconv.Atoi(bParts[i])
+		switch {
+		case aErr == nil && bErr == nil && aNumber != bNumber:
+			return aNumber - bNumber
+		case (aErr != nil || bErr != nil) && aParts[i] != bParts[i]:
+			return strings.Compare(aParts[i], bParts[i])
+		}
+	}
+	return len(aParts) - len(bParts)
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStore(t *testing.T) (*Store, func()) {
+	dir, err := ioutil.TempDir("", "scheriff-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(dir), func() { os.RemoveAll(dir) }
+}
+
+func TestImportAndGet(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	schema, err := store.Import("../validate/testdata/swagger.json", "v1.17.0")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(store.Dir(), "1.17.0", "swagger.json"), schema)
+	crd, err := store.Import("../../cmd/testdata/crds/crontab_without_default_val.yaml", "1.17.0")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(store.Dir(), "1.17.0", "crds", "crontab_without_default_val.yaml"), crd)
+
+	version, err := store.Get("v1.17.0")
+
+	assert.NoError(t, err)
+	assert.Equal(t, Version{Version: "1.17.0", Schema: schema, Crds: []string{crd}}, version)
+}
+
+func TestGetNotFound(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	_, err := store.Get("1.24.3")
+
+	assert.EqualError(t, err, "Kubernetes version '1.24.3' not found in the schema store "+store.Dir()+", use 'scheriff schema import <file> --version 1.24.3' to add it")
+}
+
+func TestImportInvalidVersion(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	_, err := store.Import("../validate/testdata/swagger.json", "")
+
+	assert.EqualError(t, err, "Invalid empty Kubernetes version")
+}
+
+func TestList(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	for _, version := range []string{"1.9.0", "1.21.1", "1.10.0"} {
+		_, err := store.Import("../validate/testdata/swagger.json", version)
+		assert.NoError(t, err)
+	}
+
+	versions, err := store.List()
+
+	assert.NoError(t, err)
+	names := make([]string, 0, len(versions))
+	for _, version := range versions {
+		names = append(names, version.Version)
+	}
+	assert.Equal(t, []string{"1.9.0", "1.10.0", "1.21.1"}, names)
+}
+
+func TestListMissingStore(t *testing.T) {
+	versions, err := New(filepath.Join(os.TempDir(), "scheriff-missing-store")).List()
+
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+}
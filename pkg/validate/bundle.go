@@ -0,0 +1,134 @@
+package validate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemaBundleFormat is increased whenever the contents of the bundles change, so that old bundles are rejected
+const schemaBundleFormat = 4
+
+// SchemaBundle holds the schemas of an OpenApiValidator, already adapted to Kubernetes validation, so that
+// they can be loaded without parsing, converting and adapting the original OpenAPI specs again
+type SchemaBundle struct {
+	Format int `json:"format"`
+	// Checksum identifies the contents of the sources the bundle was compiled from
+	Checksum string `json:"checksum"`
+	// Sources are the files the bundle was compiled from (OpenAPI specs and CustomResourceDefinitions), relative to the bundle
+	Sources           []string `json:"sources"`
+	KubernetesVersion string   `json:"kubernetesVersion,omitempty"`
+	// Components are the named schemas of the specs, which may reference each other
+	Components map[string]*openapi3.SchemaRef `json:"components"`
+	// Kinds maps every kind to the name of its schema in Components
+	Kinds        map[string]string `json:"kinds"`
+	Deprecations map[string]string `json:"deprecations,omitempty"`
+	// Namespaced tells whether each kind is namespaced, for the kinds whose scope is known
+	Namespaced map[string]bool `json:"namespaced,omitempty"`
+}
+
+// Bundle returns the schemas of the validator as a SchemaBundle, identifying the sources it was built from
+func (oeValidator OpenApiValidator) Bundle(sources []string, checksum string) *SchemaBundle {
+	componentNames := make(map[*openapi3.Schema]string, len(oeValidator.components))
+	for name, component := range oeValidator.components {
+		componentNames[component.Value] = name
+	}
+	bundle := &SchemaBundle{
+		Format:            schemaBundleFormat,
+		Checksum:          checksum,
+		Sources:           sources,
+		KubernetesVersion: oeValidator.kubernetesVersion,
+		Components:        make(map[string]*openapi3.SchemaRef, len(oeValidator.components)),
+		Kinds:             make(map[string]string, len(oeValidator.schemaCache)),
+		Deprecations:      oeValidator.deprecations,
+		Namespaced:        oeValidator.namespaced,
+	}
+	for name, component := range oeValidator.components {
+		bundle.Components[name] = component
+	}
+	for kind, schema := range oeValidator.schemaCache {
+		name, ok := componentNames[schema]
+		if !ok {
+			// schemas that aren't part of the specs (ie: the ones of CRDs) are added as components named after their kind
+			name = kind
+			bundle.Components[name] = &openapi3.SchemaRef{Value: schema}
+		}
+		bundle.Kinds[kind] = name
+	}
+	return bundle
+}
+
+// Write writes the bundle as gzipped JSON
+func (bundle *SchemaBundle) Write(writer io.Writer) error {
+	gzipWriter := gzip.NewWriter(writer)
+	err := json.NewEncoder(gzipWriter).Encode(bundle)
+	if err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// IsSchemaBundle tells if the given bytes look like a SchemaBundle (ie: they are gzipped)
+func IsSchemaBundle(bundleBytes []byte) bool {
+	return len(bundleBytes) > 2 && bundleBytes[0] == 0x1f && bundleBytes[1] == 0x8b
+}
+
+// ReadSchemaBundle reads a SchemaBundle written by SchemaBundle.Write
+func ReadSchemaBundle(bundleBytes []byte) (*SchemaBundle, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(bundleBytes))
+	if err != nil {
+		return nil, err
+	}
+	jsonBytes, err := ioutil.ReadAll(gzipReader)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &SchemaBundle{}
+	err = json.Unmarshal(jsonBytes, bundle)
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Format != schemaBundleFormat {
+		return nil, fmt.Errorf("Unsupported schema bundle format %d, expected %d: compile the bundle again", bundle.Format, schemaBundleFormat)
+	}
+	return bundle, nil
+}
+
+// NewBundleValidator builds an OpenApiValidator from the schemas of a SchemaBundle
+func NewBundleValidator(bundle *SchemaBundle, options ...OpenApiValidatorOption) (*OpenApiValidator, error) {
+	swagger3 := &openapi3.Swagger{
+		OpenAPI: "3.0.0",
+		Info:    &openapi3.Info{Title: "Kubernetes", Version: bundle.KubernetesVersion},
+		Components: openapi3.Components{
+			Schemas: bundle.Components,
+		},
+	}
+	err := openapi3.NewSwaggerLoader().ResolveRefsIn(swagger3, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaCache := make(map[string]*openapi3.Schema, len(bundle.Kinds))
+	for kind, name := range bundle.Kinds {
+		component, ok := bundle.Components[name]
+		if !ok || component.Value == nil {
+			return nil, fmt.Errorf("Schema '%s' of kind '%s' not found in bundle", name, kind)
+		}
+		schemaCache[kind] = component.Value
+	}
+
+	oeValidator := newOpenApiValidator(schemaCache, bundle.KubernetesVersion, options)
+	oeValidator.components = bundle.Components
+	for kind, notice := range bundle.Deprecations {
+		oeValidator.deprecations[kind] = notice
+	}
+	for kind, namespaced := range bundle.Namespaced {
+		oeValidator.namespaced[kind] = namespaced
+	}
+	return oeValidator, nil
+}
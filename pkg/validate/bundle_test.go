@@ -0,0 +1,69 @@
+package validate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/fllaca/scheriff/pkg/kubernetes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaBundle(t *testing.T) {
+	validator, err := NewOpenApi2Validator(loadTestSwagger(t))
+	assert.NoError(t, err)
+	err = validator.AddCrdSchemas(kubernetes.Resource{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"spec": map[string]interface{}{
+			"group": "stable.example.com",
+			"names": map[string]interface{}{"kind": "CronTab", "plural": "crontabs"},
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1", "served": true, "storage": true, "schema": map[string]interface{}{"openAPIV3Schema": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"spec": map[string]interface{}{"type": "object", "properties": map[string]interface{}{"replicas": map[string]interface{}{"type": "integer"}}}},
+				}}},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	bundleBytes := &bytes.Buffer{}
+	err = validator.Bundle([]string{"testdata/swagger.json"}, "checksum").Write(bundleBytes)
+	assert.NoError(t, err)
+	assert.True(t, IsSchemaBundle(bundleBytes.Bytes()))
+	bundle, err := ReadSchemaBundle(bundleBytes.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, "checksum", bundle.Checksum)
+	assert.Equal(t, []string{"testdata/swagger.json"}, bundle.Sources)
+	assert.Equal(t, "v1.17.0", bundle.KubernetesVersion)
+	bundleValidator, err := NewBundleValidator(bundle)
+	assert.NoError(t, err)
+
+	resources := []map[string]interface{}{
+		{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "test", "unknown": "field"}, "data": map[string]interface{}{"key": float64(1)}},
+		{"apiVersion": "v1", "kind": "Service", "metadata": map[string]interface{}{"name": "test"}, "spec": map[string]interface{}{"ports": []interface{}{map[string]interface{}{"port": "80", "targetPort": "http"}}}},
+		{"apiVersion": "stable.example.com/v1", "kind": "CronTab", "metadata": map[string]interface{}{"name": "test"}, "spec": map[string]interface{}{"replicas": "1"}},
+		{"apiVersion": "v1", "kind": "Unknown", "metadata": map[string]interface{}{"name": "test"}},
+	}
+	for _, resource := range resources {
+		assert.Equal(t, validator.Validate(resource), bundleValidator.Validate(resource))
+	}
+}
+
+func TestReadSchemaBundleUnsupportedFormat(t *testing.T) {
+	bundleBytes := &bytes.Buffer{}
+	writer := gzip.NewWriter(bundleBytes)
+	_, err := writer.Write([]byte(`{"format": 0}`))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	_, err = ReadSchemaBundle(bundleBytes.Bytes())
+
+	assert.EqualError(t, err, "Unsupported schema bundle format 0, expected 4: compile the bundle again")
+}
+
+func TestIsSchemaBundle(t *testing.T) {
+	assert.False(t, IsSchemaBundle(loadTestSwagger(t)))
+	assert.False(t, IsSchemaBundle([]byte{}))
+}
@@ -0,0 +1,28 @@
+package validate
+
+// chainValidator validates resources with several ResourceValidators
+type chainValidator []ResourceValidator
+
+// NewChainValidator returns a ResourceValidator that gathers the findings of all the validators, in order.
+// The resource is only reported as valid (with the result of the first validator) when none of them finds anything.
+func NewChainValidator(validators ...ResourceValidator) ResourceValidator {
+	return chainValidator(validators)
+}
+
+func (validators chainValidator) Validate(resource map[string]interface{}) []ValidationResult {
+	var valid []ValidationResult
+	findings := make([]ValidationResult, 0)
+	for _, validator := range validators {
+		for _, result := range validator.Validate(resource) {
+			if result.Severity != SeverityOK {
+				findings = append(findings, result)
+			} else if valid == nil {
+				valid = []ValidationResult{result}
+			}
+		}
+	}
+	if len(findings) == 0 {
+		return valid
+	}
+	return findings
+}
@@ -0,0 +1,18 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainValidator(t *testing.T) {
+	valid := ValidationResult{Message: "valid", Severity: SeverityOK, Name: "test", Kind: "v1/ConfigMap"}
+	otherValid := ValidationResult{Message: "valid", Severity: SeverityOK, Name: "other", Kind: "v1/ConfigMap"}
+	violation := ValidationResult{Message: "Property 'foo' is unsupported", Severity: SeverityError, Rule: RuleSchemaViolation, Name: "test", Kind: "v1/ConfigMap"}
+	metadata := ValidationResult{Message: "metadata.name: Required value", Severity: SeverityError, Rule: RuleMetadataViolation, Name: "test", Kind: "v1/ConfigMap"}
+
+	assert.Equal(t, []ValidationResult{valid}, NewChainValidator(resultsValidator{valid}, resultsValidator{otherValid}).Validate(testResource("v1", "ConfigMap", nil)))
+	assert.Equal(t, []ValidationResult{metadata}, NewChainValidator(resultsValidator{valid}, resultsValidator{metadata}).Validate(testResource("v1", "ConfigMap", nil)))
+	assert.Equal(t, []ValidationResult{violation, metadata}, NewChainValidator(resultsValidator{violation}, resultsValidator{metadata}).Validate(testResource("v1", "ConfigMap", nil)))
+}
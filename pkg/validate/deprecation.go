@@ -0,0 +1,160 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// apiDeprecation describes the deprecation of a Kubernetes API version
+type apiDeprecation struct {
+	deprecatedIn string
+	removedIn    string
+	// replacement is the API version to migrate to, if any
+	replacement string
+}
+
+func (deprecation apiDeprecation) String() string {
+	replacement := "no replacement available"
+	if deprecation.replacement != "" {
+		replacement = fmt.Sprintf("migrate to %s", deprecation.replacement)
+	}
+	return fmt.Sprintf("deprecated in %s, removed in %s, %s", deprecation.deprecatedIn, deprecation.removedIn, replacement)
+}
+
+// knownDeprecations holds the API versions removed from Kubernetes, indexed by group/version/kind.
+// See https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+var knownDeprecations = map[string]apiDeprecation{
+	"extensions/v1beta1/DaemonSet":                                        {"1.9", "1.16", "apps/v1"},
+	"extensions/v1beta1/Deployment":                                       {"1.9", "1.16", "apps/v1"},
+	"extensions/v1beta1/ReplicaSet":                                       {"1.9", "1.16", "apps/v1"},
+	"extensions/v1beta1/NetworkPolicy":                                    {"1.9", "1.16", "networking.k8s.io/v1"},
+	"extensions/v1beta1/PodSecurityPolicy":                                {"1.11", "1.16", "policy/v1beta1"},
+	"apps/v1beta1/Deployment":                                             {"1.9", "1.16", "apps/v1"},
+	"apps/v1beta1/StatefulSet":                                            {"1.9", "1.16", "apps/v1"},
+	"apps/v1beta2/DaemonSet":                                              {"1.9", "1.16", "apps/v1"},
+	"apps/v1beta2/Deployment":                                             {"1.9", "1.16", "apps/v1"},
+	"apps/v1beta2/ReplicaSet":                                             {"1.9", "1.16", "apps/v1"},
+	"apps/v1beta2/StatefulSet":                                            {"1.9", "1.16", "apps/v1"},
+	"extensions/v1beta1/Ingress":                                          {"1.14", "1.22", "networking.k8s.io/v1"},
+	"networking.k8s.io/v1beta1/Ingress":                                   {"1.19", "1.22", "networking.k8s.io/v1"},
+	"networking.k8s.io/v1beta1/IngressClass":                              {"1.19", "1.22", "networking.k8s.io/v1"},
+	"apiextensions.k8s.io/v1beta1/CustomResourceDefinition":               {"1.16", "1.22", "apiextensions.k8s.io/v1"},
+	"admissionregistration.k8s.io/v1beta1/MutatingWebhookConfiguration":   {"1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	"admissionregistration.k8s.io/v1beta1/ValidatingWebhookConfiguration": {"1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1/ClusterRole":                       {"1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1/ClusterRoleBinding":                {"1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1/Role":                              {"1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1/RoleBinding":                       {"1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	"scheduling.k8s.io/v1beta1/PriorityClass":                             {"1.14", "1.22", "scheduling.k8s.io/v1"},
+	"certificates.k8s.io/v1beta1/CertificateSigningRequest":               {"1.19", "1.22", "certificates.k8s.io/v1"},
+	"batch/v1beta1/CronJob":                                               {"1.21", "1.25", "batch/v1"},
+	"discovery.k8s.io/v1beta1/EndpointSlice":                              {"1.21", "1.25", "discovery.k8s.io/v1"},
+	"events.k8s.io/v1beta1/Event":                                         {"1.19", "1.25", "events.k8s.io/v1"},
+	"autoscaling/v2beta1/HorizontalPodAutoscaler":                         {"1.22", "1.25", "autoscaling/v2"},
+	"policy/v1beta1/PodDisruptionBudget":                                  {"1.21", "1.25", "policy/v1"},
+	"policy/v1beta1/PodSecurityPolicy":                                    {"1.21", "1.25", ""},
+	"node.k8s.io/v1beta1/RuntimeClass":                                    {"1.20", "1.25", "node.k8s.io/v1"},
+	"autoscaling/v2beta2/HorizontalPodAutoscaler":                         {"1.23", "1.26", "autoscaling/v2"},
+}
+
+var kubernetesVersionRegexp = regexp.MustCompile(`^v?(\d+)\.(\d+)`)
+
+// kubernetesVersion is the minor version of a Kubernetes release (ie: 1.22)
+type kubernetesVersion struct {
+	major int
+	minor int
+}
+
+// parseKubernetesVersion reads the minor version from strings like "v1.17.0", "1.22" or "v1.21.3+k3s1"
+func parseKubernetesVersion(version string) (kubernetesVersion, bool) {
+	matches := kubernetesVersionRegexp.FindStringSubmatch(version)
+	if matches == nil {
+		return kubernetesVersion{}, false
+	}
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	return kubernetesVersion{major: major, minor: minor}, true
+}
+
+// atLeast tells if the version is the same or newer than the given one
+func (version kubernetesVersion) atLeast(other string) bool {
+	otherVersion, ok := parseKubernetesVersion(other)
+	if !ok {
+		return false
+	}
+	if version.major != otherVersion.major {
+		return version.major > otherVersion.major
+	}
+	return version.minor >= otherVersion.minor
+}
+
+// deprecationNotice returns the deprecation notice of the schema of a kind, either from the
+// "x-kubernetes-deprecated" and "x-kubernetes-deprecation-warning" hints, which mirror the fields of the CRD versions,
+// or from its description, which Kubernetes starts with "DEPRECATED"
+// (ie: "DEPRECATED - This group version of Deployment is deprecated by apps/v1/Deployment. See...")
+func deprecationNotice(kind string, schema *openapi3.Schema) string {
+	var warning string
+	if extensionValue(schema, "x-kubernetes-deprecation-warning", &warning) && warning != "" {
+		return warning
+	}
+	var deprecated bool
+	if extensionValue(schema, "x-kubernetes-deprecated", &deprecated) && deprecated {
+		return fmt.Sprintf("%s is deprecated", kind)
+	}
+	description := strings.TrimSpace(schema.Description)
+	if !strings.HasPrefix(description, "DEPRECATED") {
+		return ""
+	}
+	notice := strings.TrimLeft(strings.TrimPrefix(description, "DEPRECATED"), " -:")
+	if end := strings.Index(notice, ". "); end >= 0 {
+		notice = notice[:end]
+	}
+	return strings.TrimSuffix(notice, ".")
+}
+
+// extensionValue decodes the extension 'name' of a schema into 'value', telling whether it was found with the type of 'value'
+func extensionValue(schema *openapi3.Schema, name string, value interface{}) bool {
+	data, ok := schema.ExtensionProps.Extensions[name].(json.RawMessage)
+	return ok && json.Unmarshal(data, value) == nil
+}
+
+// crdDeprecations holds the deprecation fields of the versions of a CustomResourceDefinition
+type crdDeprecations struct {
+	Spec struct {
+		Versions []struct {
+			Name               string  `json:"name"`
+			Deprecated         bool    `json:"deprecated"`
+			DeprecationWarning *string `json:"deprecationWarning"`
+		} `json:"versions"`
+	} `json:"spec"`
+}
+
+// checkDeprecation looks for the deprecation of a kind, either in the schemas or in the table of known deprecations.
+// It returns nil when the kind is not deprecated in the Kubernetes version of the schemas.
+func (oeValidator OpenApiValidator) checkDeprecation(result ValidationResult, kindFound bool) *ValidationResult {
+	version, versionKnown := parseKubernetesVersion(oeValidator.kubernetesVersion)
+	deprecation, known := knownDeprecations[result.Kind]
+	switch {
+	case known && !kindFound && (!versionKnown || version.atLeast(deprecation.removedIn)):
+		result.Message = fmt.Sprintf("Kind '%s' %s", result.Kind, deprecation)
+		result.Severity = SeverityError
+		result.Rule = RuleRemovedApi
+		return &result
+	case !kindFound:
+		return nil
+	case known && (!versionKnown || version.atLeast(deprecation.deprecatedIn)):
+		result.Message = fmt.Sprintf("Kind '%s' %s", result.Kind, deprecation)
+	case oeValidator.deprecations[result.Kind] != "":
+		result.Message = fmt.Sprintf("Kind '%s' is deprecated: %s", result.Kind, oeValidator.deprecations[result.Kind])
+	default:
+		return nil
+	}
+	result.Severity = SeverityWarning
+	result.Rule = RuleDeprecatedApi
+	return &result
+}
@@ -0,0 +1,188 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/fllaca/scheriff/pkg/kubernetes"
+	"github.com/stretchr/testify/assert"
+)
+
+const deprecationsTestSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "Kubernetes", "version": "v1.21.0"},
+  "paths": {},
+  "definitions": {
+    "io.k8s.api.batch.v1beta1.CronJob": {
+      "description": "CronJob represents the configuration of a single cron job.",
+      "type": "object",
+      "properties": {"apiVersion": {"type": "string"}, "kind": {"type": "string"}, "metadata": {"type": "object"}},
+      "x-kubernetes-group-version-kind": [{"group": "batch", "kind": "CronJob", "version": "v1beta1"}]
+    },
+    "io.example.v1alpha1.Widget": {
+      "description": "DEPRECATED - This group version of Widget is deprecated by example.io/v1/Widget. See the release notes for more information. Widget is an example.",
+      "type": "object",
+      "properties": {"apiVersion": {"type": "string"}, "kind": {"type": "string"}, "metadata": {"type": "object"}},
+      "x-kubernetes-group-version-kind": [{"group": "example.io", "kind": "Widget", "version": "v1alpha1"}]
+    },
+    "io.example.v1beta1.Widget": {
+      "description": "Widget is an example.",
+      "type": "object",
+      "properties": {"apiVersion": {"type": "string"}, "kind": {"type": "string"}, "metadata": {"type": "object"}},
+      "x-kubernetes-group-version-kind": [{"group": "example.io", "kind": "Widget", "version": "v1beta1"}],
+      "x-kubernetes-deprecated": true,
+      "x-kubernetes-deprecation-warning": "example.io/v1beta1 Widget is deprecated; use example.io/v1 Widget"
+    },
+    "io.example.v1beta2.Widget": {
+      "description": "Widget is an example.",
+      "type": "object",
+      "properties": {"apiVersion": {"type": "string"}, "kind": {"type": "string"}, "metadata": {"type": "object"}},
+      "x-kubernetes-group-version-kind": [{"group": "example.io", "kind": "Widget", "version": "v1beta2"}],
+      "x-kubernetes-deprecated": true
+    }
+  }
+}`
+
+func TestOpenApiValidatorDeprecations(t *testing.T) {
+	tests := []struct {
+		name     string
+		specs    []byte
+		resource map[string]interface{}
+		expected []ValidationResult
+	}{
+		{
+			name:     "deprecated in the version of the schema",
+			specs:    []byte(deprecationsTestSwagger),
+			resource: testResource("batch/v1beta1", "CronJob", nil),
+			expected: []ValidationResult{
+				{Message: "Kind 'batch/v1beta1/CronJob' deprecated in 1.21, removed in 1.25, migrate to batch/v1", Severity: SeverityWarning, Rule: RuleDeprecatedApi, Name: "test", Kind: "batch/v1beta1/CronJob"},
+			},
+		},
+		{
+			name:     "deprecated in the schema description",
+			specs:    []byte(deprecationsTestSwagger),
+			resource: testResource("example.io/v1alpha1", "Widget", nil),
+			expected: []ValidationResult{
+				{Message: "Kind 'example.io/v1alpha1/Widget' is deprecated: This group version of Widget is deprecated by example.io/v1/Widget", Severity: SeverityWarning, Rule: RuleDeprecatedApi, Name: "test", Kind: "example.io/v1alpha1/Widget"},
+			},
+		},
+		{
+			name:     "deprecation warning hint of the schema",
+			specs:    []byte(deprecationsTestSwagger),
+			resource: testResource("example.io/v1beta1", "Widget", nil),
+			expected: []ValidationResult{
+				{Message: "Kind 'example.io/v1beta1/Widget' is deprecated: example.io/v1beta1 Widget is deprecated; use example.io/v1 Widget", Severity: SeverityWarning, Rule: RuleDeprecatedApi, Name: "test", Kind: "example.io/v1beta1/Widget"},
+			},
+		},
+		{
+			name:     "deprecated hint of the schema",
+			specs:    []byte(deprecationsTestSwagger),
+			resource: testResource("example.io/v1beta2", "Widget", nil),
+			expected: []ValidationResult{
+				{Message: "Kind 'example.io/v1beta2/Widget' is deprecated: example.io/v1beta2/Widget is deprecated", Severity: SeverityWarning, Rule: RuleDeprecatedApi, Name: "test", Kind: "example.io/v1beta2/Widget"},
+			},
+		},
+		{
+			name:     "removed before the version of the schema",
+			specs:    loadTestSwagger(t),
+			resource: testResource("extensions/v1beta1", "Deployment", nil),
+			expected: []ValidationResult{
+				{Message: "Kind 'extensions/v1beta1/Deployment' deprecated in 1.9, removed in 1.16, migrate to apps/v1", Severity: SeverityError, Rule: RuleRemovedApi, Name: "test", Kind: "extensions/v1beta1/Deployment"},
+			},
+		},
+		{
+			name:     "not removed yet in the version of the schema",
+			specs:    loadTestSwagger(t),
+			resource: testResource("extensions/v1beta1", "Ingress", nil),
+			expected: []ValidationResult{
+				{Message: "Kind 'extensions/v1beta1/Ingress' not found in schema", Severity: SeverityWarning, Rule: RuleUnknownKind, Name: "test", Kind: "extensions/v1beta1/Ingress"},
+			},
+		},
+		{
+			name:     "not deprecated",
+			specs:    loadTestSwagger(t),
+			resource: testResource("v1", "ConfigMap", nil),
+			expected: []ValidationResult{
+				{Message: "valid", Severity: SeverityOK, Name: "test", Kind: "v1/ConfigMap"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validator, err := NewOpenApi2Validator(test.specs, WithDeprecationChecks(true))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, validator.Validate(test.resource))
+		})
+	}
+}
+
+func TestOpenApiValidatorDeprecationsDisabled(t *testing.T) {
+	validator, err := NewOpenApi2Validator([]byte(deprecationsTestSwagger))
+	assert.NoError(t, err)
+
+	results := validator.Validate(testResource("batch/v1beta1", "CronJob", nil))
+
+	assert.Equal(t, []ValidationResult{{Message: "valid", Severity: SeverityOK, Name: "test", Kind: "batch/v1beta1/CronJob"}}, results)
+}
+
+func TestOpenApiValidatorCrdDeprecations(t *testing.T) {
+	validator, err := NewOpenApi2Validator(loadTestSwagger(t), WithDeprecationChecks(true))
+	assert.NoError(t, err)
+	crd := kubernetes.Resource{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "crontabs.stable.example.com"},
+		"spec": map[string]interface{}{
+			"group": "stable.example.com",
+			"names": map[string]interface{}{"kind": "CronTab", "plural": "crontabs"},
+			"scope": "Namespaced",
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1beta1", "served": true, "storage": false, "deprecated": true, "deprecationWarning": "stable.example.com/v1beta1 CronTab is deprecated; use stable.example.com/v1 CronTab",
+					"schema": map[string]interface{}{"openAPIV3Schema": map[string]interface{}{"type": "object"}}},
+				map[string]interface{}{"name": "v1alpha1", "served": true, "storage": false, "deprecated": true,
+					"schema": map[string]interface{}{"openAPIV3Schema": map[string]interface{}{"type": "object"}}},
+				map[string]interface{}{"name": "v1", "served": true, "storage": true,
+					"schema": map[string]interface{}{"openAPIV3Schema": map[string]interface{}{"type": "object"}}},
+			},
+		},
+	}
+
+	err = validator.AddCrdSchemas(crd)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ValidationResult{
+		{Message: "Kind 'stable.example.com/v1beta1/CronTab' is deprecated: stable.example.com/v1beta1 CronTab is deprecated; use stable.example.com/v1 CronTab", Severity: SeverityWarning, Rule: RuleDeprecatedApi, Name: "test", Kind: "stable.example.com/v1beta1/CronTab"},
+	}, validator.Validate(testResource("stable.example.com/v1beta1", "CronTab", nil)))
+	assert.Equal(t, []ValidationResult{
+		{Message: "Kind 'stable.example.com/v1alpha1/CronTab' is deprecated: stable.example.com/v1alpha1 CronTab is deprecated", Severity: SeverityWarning, Rule: RuleDeprecatedApi, Name: "test", Kind: "stable.example.com/v1alpha1/CronTab"},
+	}, validator.Validate(testResource("stable.example.com/v1alpha1", "CronTab", nil)))
+	assert.Equal(t, []ValidationResult{
+		{Message: "valid", Severity: SeverityOK, Name: "test", Kind: "stable.example.com/v1/CronTab"},
+	}, validator.Validate(testResource("stable.example.com/v1", "CronTab", nil)))
+}
+
+func TestParseKubernetesVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		other    string
+		ok       bool
+		expected bool
+	}{
+		{version: "v1.17.0", other: "1.16", ok: true, expected: true},
+		{version: "v1.17.0", other: "1.17", ok: true, expected: true},
+		{version: "1.21", other: "1.22", ok: true, expected: false},
+		{version: "v1.21.3+k3s1", other: "1.9", ok: true, expected: true},
+		{version: "v2.0.0", other: "1.25", ok: true, expected: true},
+		{version: "unversioned", ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			version, ok := parseKubernetesVersion(test.version)
+			assert.Equal(t, test.ok, ok)
+			if ok {
+				assert.Equal(t, test.expected, version.atLeast(test.other))
+			}
+		})
+	}
+}
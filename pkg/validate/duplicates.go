@@ -0,0 +1,65 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/fllaca/scheriff/pkg/kubernetes"
+	"github.com/fllaca/scheriff/pkg/utils"
+)
+
+// DuplicatesValidator reports the resources defined more than once, as applying them makes the last one override the others.
+// Resources are the same when they have the same API group, kind, namespace and name (regardless of their API version).
+type DuplicatesValidator struct {
+	defaultNamespace string
+}
+
+// NewDuplicatesValidator creates a DuplicatesValidator, where the resources without namespace belong to 'defaultNamespace'
+func NewDuplicatesValidator(defaultNamespace string) DuplicatesValidator {
+	return DuplicatesValidator{
+		defaultNamespace: defaultNamespace,
+	}
+}
+
+// Validate reports every definition of a resource but the first one, along with the location of the first one
+func (validator DuplicatesValidator) Validate(resources []LocatedResource) []ValidationResult {
+	results := make([]ValidationResult, 0)
+	first := make(map[string]LocatedResource)
+	for _, located := range resources {
+		name := kubernetes.GetName(located.Resource)
+		if name == "" || kubernetes.GetString(located.Resource, "kind") == "" {
+			continue
+		}
+		namespace := resourceNamespace(located.Resource, validator.defaultNamespace)
+		key := fmt.Sprintf("%s/%s/%s", groupKind(located.Resource), namespace, name)
+		original, ok := first[key]
+		if !ok {
+			first[key] = located
+			continue
+		}
+		results = append(results, located.finding(SeverityError, RuleDuplicateResource, nil,
+			fmt.Sprintf("%s '%s' is already defined in %s", kubernetes.GetString(located.Resource, "kind"), utils.JoinNotEmptyStrings("/", namespace, name), original.location())))
+	}
+	return results
+}
+
+// resourceNamespace returns the namespace of a resource, or the default one when it isn't set
+func resourceNamespace(resource map[string]interface{}, defaultNamespace string) string {
+	if namespace := kubernetes.GetNamespace(resource); namespace != "" {
+		return namespace
+	}
+	return defaultNamespace
+}
+
+// location describes where the resource is defined (ie: "app.yaml at line 3"), to be referenced in the findings of other resources
+func (located LocatedResource) location() string {
+	location := located.Source
+	if located.Item != nil {
+		location = fmt.Sprintf("%s (item %d)", location, *located.Item)
+	}
+	if line, _ := findPosition(located.node, ""); line > 0 {
+		location = fmt.Sprintf("%s at line %d", location, line)
+	} else {
+		location = fmt.Sprintf("%s (document %d)", location, located.Document)
+	}
+	return location
+}
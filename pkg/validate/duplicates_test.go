@@ -0,0 +1,65 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplicatesValidator(t *testing.T) {
+	resources := readTestResources(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: app
+    namespace: prod
+- apiVersion: apps/v1beta2
+  kind: Deployment
+  metadata:
+    name: app
+    namespace: default
+`)
+	other := readSourceResources(t, "other.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  namespace: default
+`)
+	item := 1
+
+	results := NewDuplicatesValidator("default").Validate(append(resources, other...))
+
+	assert.Equal(t, []ValidationResult{
+		{Message: "Deployment 'default/app' is already defined in test.yaml at line 1", Severity: SeverityError, Rule: RuleDuplicateResource, Name: "app", Namespace: "default", Kind: "apps/v1beta2/Deployment", Source: "test.yaml", Document: 2, Item: &item, Line: 19, Column: 3},
+		{Message: "ConfigMap 'default/app' is already defined in test.yaml at line 6", Severity: SeverityError, Rule: RuleDuplicateResource, Name: "app", Namespace: "default", Kind: "v1/ConfigMap", Source: "other.yaml", Document: 0, Line: 1, Column: 1},
+	}, results)
+}
+
+func TestDuplicatesValidatorDefaultNamespace(t *testing.T) {
+	resources := readTestResources(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  namespace: default
+`)
+
+	assert.Empty(t, NewDuplicatesValidator("example").Validate(resources))
+	assert.Len(t, NewDuplicatesValidator("default").Validate(resources), 1)
+}
@@ -0,0 +1,59 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const quantitySchemaName = "io.k8s.apimachinery.pkg.api.resource.Quantity"
+
+// kubernetesFormats are the string formats used in the Kubernetes schemas (and CRDs), as the regular expressions equivalent
+// to the way Kubernetes parses them. They are checked by the schema walker, on top of the laxer ones of kin-openapi for the
+// formats defined by both, without changing the formats defined in kin-openapi (which are global).
+var kubernetesFormats = map[string]*regexp.Regexp{
+	// resource.Quantity: a decimal number followed by a binary SI suffix (Ki, Mi...), a decimal SI suffix (m, k, M...) or an exponent
+	"quantity": regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([numkMGTPE]|[KMGTPE]i|[eE][+-]?[0-9]+)?$`),
+	// the strings of intstr.IntOrString fields: numbers, percentages (ie: maxSurge) or port names
+	"int-or-string": regexp.MustCompile(`^([+-]?[0-9]+%?|[a-z0-9]([-a-z0-9]*[a-z0-9])?)$`),
+	// metav1.Time is parsed as RFC 3339, which requires the time zone
+	"date-time": regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}[Tt][0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?([Zz]|[+-][0-9]{2}:[0-9]{2})$`),
+	// []byte fields (ie: the data of Secrets) are standard base64 with padding
+	"byte": regexp.MustCompile(`^([A-Za-z0-9+/]{4})*([A-Za-z0-9+/]{2}==|[A-Za-z0-9+/]{3}=)?$`),
+	// metav1.Duration is parsed with time.ParseDuration
+	"duration": regexp.MustCompile(`^[+-]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`),
+}
+
+// formatViolations checks a string value against the Kubernetes format of its schema, if it has one and no "pattern"
+// (which takes precedence over the format, as in kin-openapi)
+func formatViolations(schema *openapi3.Schema, value interface{}, path []string) []schemaViolation {
+	str, ok := value.(string)
+	if !ok || schema.Pattern != "" {
+		return nil
+	}
+	format, ok := kubernetesFormats[schema.Format]
+	if !ok || format.MatchString(str) {
+		return nil
+	}
+	return []schemaViolation{{
+		path:  path,
+		value: value,
+		err: &openapi3.SchemaError{
+			Value:       value,
+			Schema:      schema,
+			SchemaField: "format",
+			Reason:      fmt.Sprintf("JSON string doesn't match the format '%s (regular expression `%s`)'", schema.Format, format),
+		},
+	}}
+}
+
+// quantitySchema accepts numbers and strings with the "quantity" format, as Kubernetes does for resource.Quantity fields
+// (which are only described as strings in the schemas)
+func quantitySchema() *openapi3.SchemaRef {
+	return &openapi3.SchemaRef{
+		Value: openapi3.NewOneOfSchema(
+			openapi3.NewStringSchema().WithFormat("quantity"),
+			&openapi3.Schema{Type: "number"}),
+	}
+}
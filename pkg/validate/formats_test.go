@@ -0,0 +1,106 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+const formatsTestSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "Kubernetes", "version": "v1.17.0"},
+  "paths": {},
+  "definitions": {
+    "io.k8s.apimachinery.pkg.api.resource.Quantity": {"type": "string"},
+    "io.k8s.apimachinery.pkg.util.intstr.IntOrString": {"type": "string", "format": "int-or-string"},
+    "io.example.v1.Widget": {
+      "type": "object",
+      "properties": {
+        "apiVersion": {"type": "string"},
+        "kind": {"type": "string"},
+        "metadata": {"type": "object"},
+        "cpu": {"$ref": "#/definitions/io.k8s.apimachinery.pkg.api.resource.Quantity"},
+        "port": {"$ref": "#/definitions/io.k8s.apimachinery.pkg.util.intstr.IntOrString"},
+        "time": {"type": "string", "format": "date-time"},
+        "data": {"type": "object", "additionalProperties": {"type": "string", "format": "byte"}},
+        "timeout": {"type": "string", "format": "duration"}
+      },
+      "x-kubernetes-group-version-kind": [{"group": "example.io", "kind": "Widget", "version": "v1"}]
+    }
+  }
+}`
+
+func TestOpenApiValidatorFormats(t *testing.T) {
+	tests := []struct {
+		field   string
+		valid   []interface{}
+		invalid []interface{}
+	}{
+		{field: "cpu", valid: []interface{}{"500m", "1.5Gi", "2", ".5", "1e3", float64(1), 0.5}, invalid: []interface{}{"500mm", "1.5Gii", "1 Gi", "Gi", true}},
+		{field: "port", valid: []interface{}{"http", "8080", "25%", float64(80)}, invalid: []interface{}{"HTTP", "-http", "25 %"}},
+		{field: "time", valid: []interface{}{"2020-08-01T10:00:00Z", "2020-08-01T10:00:00.5+02:00"}, invalid: []interface{}{"2020-08-01", "2020-08-01T10:00:00"}},
+		{field: "timeout", valid: []interface{}{"0", "30s", "1h30m", "1.5h", "-10ms"}, invalid: []interface{}{"30", "1d", "s"}},
+	}
+
+	validator, err := NewOpenApi2Validator([]byte(formatsTestSwagger))
+	assert.NoError(t, err)
+	for _, test := range tests {
+		t.Run(test.field, func(t *testing.T) {
+			for _, value := range test.valid {
+				widget := testResource("example.io/v1", "Widget", nil)
+				widget[test.field] = value
+				results := validator.Validate(widget)
+				assert.Equal(t, SeverityOK, results[0].Severity, "%v should be valid: %v", value, results)
+			}
+			for _, value := range test.invalid {
+				widget := testResource("example.io/v1", "Widget", nil)
+				widget[test.field] = value
+				results := validator.Validate(widget)
+				assert.Len(t, results, 1)
+				assert.Equal(t, SeverityError, results[0].Severity, "%v should be invalid", value)
+				assert.Equal(t, "/"+test.field, results[0].Path)
+			}
+		})
+	}
+}
+
+func TestOpenApiValidatorByteFormat(t *testing.T) {
+	validator, err := NewOpenApi2Validator([]byte(formatsTestSwagger))
+	assert.NoError(t, err)
+	widget := testResource("example.io/v1", "Widget", nil)
+	widget["data"] = map[string]interface{}{"valid": "dmFsdWU=", "empty": "", "invalid": "value"}
+
+	results := validator.Validate(widget)
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, SeverityError, results[0].Severity)
+	assert.Equal(t, "/data/invalid", results[0].Path)
+	assert.Contains(t, results[0].Message, "doesn't match the format 'byte")
+}
+
+func TestOpenApiValidatorQuantityMessage(t *testing.T) {
+	validator, err := NewOpenApi2Validator([]byte(formatsTestSwagger))
+	assert.NoError(t, err)
+	widget := testResource("example.io/v1", "Widget", nil)
+	widget["cpu"] = "500mm"
+
+	results := validator.Validate(widget)
+
+	assert.Len(t, results, 1)
+	assert.Contains(t, results[0].Message, "doesn't match the format 'quantity")
+	assert.Equal(t, `"500mm"`, results[0].Actual)
+}
+
+func TestOpenApiValidatorKeepsLibraryFormats(t *testing.T) {
+	_, err := NewOpenApi2Validator([]byte(formatsTestSwagger))
+	assert.NoError(t, err)
+
+	// the Kubernetes formats are checked by the validator, without defining them in kin-openapi
+	for format, pattern := range kubernetesFormats {
+		if libraryPattern, ok := openapi3.SchemaStringFormats[format]; ok {
+			assert.NotEqual(t, pattern.String(), libraryPattern.String(), format)
+		}
+	}
+	assert.NotContains(t, openapi3.SchemaStringFormats, "quantity")
+}
@@ -0,0 +1,147 @@
+package validate
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/fllaca/scheriff/pkg/kubernetes"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/api/validation/path"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// nameValidators holds the validation of the names of the kinds (by "group/kind") whose names don't need to be DNS-1123 subdomains
+var nameValidators = map[string]apivalidation.ValidateNameFunc{
+	"/Namespace":                                   apivalidation.NameIsDNSLabel,
+	"/Service":                                     apivalidation.NameIsDNS1035Label,
+	"rbac.authorization.k8s.io/Role":               path.ValidatePathSegmentName,
+	"rbac.authorization.k8s.io/ClusterRole":        path.ValidatePathSegmentName,
+	"rbac.authorization.k8s.io/RoleBinding":        path.ValidatePathSegmentName,
+	"rbac.authorization.k8s.io/ClusterRoleBinding": path.ValidatePathSegmentName,
+}
+
+// MetadataValidator validates the metadata of the resources with the rules the Kubernetes API applies to any object, which
+// aren't part of the OpenAPI schemas: the syntax of the name, generateName and namespace, and of the labels and annotations
+// (along with the total size of the annotations). Resources without metadata (ie: kustomization files) aren't validated.
+type MetadataValidator struct{}
+
+func NewMetadataValidator() MetadataValidator {
+	return MetadataValidator{}
+}
+
+// Validate validates the metadata of a resource. The findings are suppressed by the IgnoreAnnotation as in OpenApiValidator.
+func (metadataValidator MetadataValidator) Validate(resource map[string]interface{}) []ValidationResult {
+	if kubernetes.GetMetadata(resource) == nil {
+		return fieldErrorResults(resource, RuleMetadataViolation, nil)
+	}
+	return fieldErrorResults(resource, RuleMetadataViolation, validateMetadata(resource))
+}
+
+// fieldErrorResults converts the field errors found by the validations of apimachinery to the findings of a rule,
+// suppressed by the IgnoreAnnotation, or to a single OK result when there are none
+func fieldErrorResults(resource map[string]interface{}, rule string, errs field.ErrorList) []ValidationResult {
+	result := ValidationResult{
+		Kind:      kubernetes.GetApiVersionKind(resource),
+		Name:      kubernetes.GetName(resource),
+		Namespace: kubernetes.GetNamespace(resource),
+	}
+	if len(errs) == 0 {
+		result.Message = "valid"
+		result.Severity = SeverityOK
+		return []ValidationResult{result}
+	}
+	results := make([]ValidationResult, 0, len(errs))
+	for _, err := range errs {
+		errorResult := result
+		errorResult.Message = err.Error()
+		errorResult.Severity = SeverityError
+		errorResult.Rule = rule
+		errorResult.Path = fieldPathPointer(err.Field)
+		if value, ok := err.BadValue.(string); ok && err.Type == field.ErrorTypeInvalid {
+			actual, _ := json.Marshal(value)
+			errorResult.Actual = string(actual)
+		}
+		results = append(results, errorResult)
+	}
+	return suppressFindings(results, annotationSuppressedRules(resource))
+}
+
+func validateMetadata(resource map[string]interface{}) field.ErrorList {
+	metadata := kubernetes.GetMetadata(resource)
+	metadataPath := field.NewPath("metadata")
+	nameFn, ok := nameValidators[groupKind(resource)]
+	if !ok {
+		nameFn = apivalidation.NameIsDNSSubdomain
+	}
+
+	errs := field.ErrorList{}
+	name := kubernetes.GetString(metadata, "name")
+	generateName := kubernetes.GetString(metadata, "generateName")
+	if generateName != "" {
+		for _, msg := range nameFn(generateName, true) {
+			errs = append(errs, field.Invalid(metadataPath.Child("generateName"), generateName, msg))
+		}
+	}
+	if name == "" && generateName == "" {
+		errs = append(errs, field.Required(metadataPath.Child("name"), "name or generateName is required"))
+	}
+	if name != "" {
+		for _, msg := range nameFn(name, false) {
+			errs = append(errs, field.Invalid(metadataPath.Child("name"), name, msg))
+		}
+	}
+	if namespace := kubernetes.GetString(metadata, "namespace"); namespace != "" {
+		for _, msg := range apivalidation.ValidateNamespaceName(namespace, false) {
+			errs = append(errs, field.Invalid(metadataPath.Child("namespace"), namespace, msg))
+		}
+	}
+	errs = append(errs, metav1validation.ValidateLabels(stringMap(metadata["labels"]), metadataPath.Child("labels"))...)
+	errs = append(errs, apivalidation.ValidateAnnotations(stringMap(metadata["annotations"]), metadataPath.Child("annotations"))...)
+	return errs
+}
+
+// groupKind returns the "group/kind" of a resource, with an empty group for the core API group
+func groupKind(resource map[string]interface{}) string {
+	group := ""
+	if apiVersion := kubernetes.GetString(resource, "apiVersion"); strings.Contains(apiVersion, "/") {
+		group = apiVersion[:strings.LastIndex(apiVersion, "/")]
+	}
+	return group + "/" + kubernetes.GetString(resource, "kind")
+}
+
+// stringMap returns the string values of a map (ie: the labels of a resource), the others are reported by the schema validation
+func stringMap(value interface{}) map[string]string {
+	values, _ := value.(map[string]interface{})
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		if stringValue, ok := value.(string); ok {
+			result[key] = stringValue
+		}
+	}
+	return result
+}
+
+// fieldPathPointer converts the path of a field error (ie: "metadata.labels[app.kubernetes.io/name]") to a JSON pointer
+func fieldPathPointer(fieldPath string) string {
+	segments := make([]string, 0)
+	for fieldPath != "" {
+		var segment string
+		if strings.HasPrefix(fieldPath, "[") && strings.Contains(fieldPath, "]") {
+			// map keys and list indexes may contain dots
+			end := strings.Index(fieldPath, "]")
+			segment, fieldPath = fieldPath[1:end], fieldPath[end+1:]
+		} else {
+			end := strings.IndexAny(fieldPath, ".[")
+			if end < 0 {
+				end = len(fieldPath)
+			}
+			segment, fieldPath = fieldPath[:end], fieldPath[end:]
+		}
+		fieldPath = strings.TrimPrefix(fieldPath, ".")
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return jsonPointer(segments)
+}
@@ -0,0 +1,95 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataValidator(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource map[string]interface{}
+		expected []ValidationResult
+	}{
+		{
+			name:     "valid",
+			resource: testResource("v1", "ConfigMap", map[string]interface{}{"metadata": map[string]interface{}{"name": "app.settings", "namespace": "example", "labels": map[string]interface{}{"app.kubernetes.io/name": "app"}}}),
+			expected: []ValidationResult{{Message: "valid", Severity: SeverityOK, Name: "app.settings", Namespace: "example", Kind: "v1/ConfigMap"}},
+		},
+		{
+			name:     "without metadata",
+			resource: map[string]interface{}{"apiVersion": "kustomize.config.k8s.io/v1beta1", "kind": "Kustomization"},
+			expected: []ValidationResult{{Message: "valid", Severity: SeverityOK, Kind: "kustomize.config.k8s.io/v1beta1/Kustomization"}},
+		},
+		{
+			name:     "invalid name and namespace",
+			resource: testResource("v1", "ConfigMap", map[string]interface{}{"metadata": map[string]interface{}{"name": "App_Settings", "namespace": "my.namespace"}}),
+			expected: []ValidationResult{
+				{Message: "metadata.name: Invalid value: \"App_Settings\": a DNS-1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')", Severity: SeverityError, Rule: RuleMetadataViolation, Name: "App_Settings", Namespace: "my.namespace", Kind: "v1/ConfigMap", Path: "/metadata/name", Actual: "\"App_Settings\""},
+				{Message: "metadata.namespace: Invalid value: \"my.namespace\": a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')", Severity: SeverityError, Rule: RuleMetadataViolation, Name: "App_Settings", Namespace: "my.namespace", Kind: "v1/ConfigMap", Path: "/metadata/namespace", Actual: "\"my.namespace\""},
+			},
+		},
+		{
+			name:     "names of the kind",
+			resource: testResource("v1", "Service", map[string]interface{}{"metadata": map[string]interface{}{"name": "app.web"}}),
+			expected: []ValidationResult{
+				{Message: "metadata.name: Invalid value: \"app.web\": a DNS-1035 label must consist of lower case alphanumeric characters or '-', start with an alphabetic character, and end with an alphanumeric character (e.g. 'my-name',  or 'abc-123', regex used for validation is '[a-z]([-a-z0-9]*[a-z0-9])?')", Severity: SeverityError, Rule: RuleMetadataViolation, Name: "app.web", Kind: "v1/Service", Path: "/metadata/name", Actual: "\"app.web\""},
+			},
+		},
+		{
+			name:     "path segment names",
+			resource: testResource("rbac.authorization.k8s.io/v1", "ClusterRole", map[string]interface{}{"metadata": map[string]interface{}{"name": "system:controller:app"}}),
+			expected: []ValidationResult{{Message: "valid", Severity: SeverityOK, Name: "system:controller:app", Kind: "rbac.authorization.k8s.io/v1/ClusterRole"}},
+		},
+		{
+			name:     "generate name",
+			resource: testResource("batch/v1", "Job", map[string]interface{}{"metadata": map[string]interface{}{"generateName": "Migration-"}}),
+			expected: []ValidationResult{
+				{Message: "metadata.generateName: Invalid value: \"Migration-\": a DNS-1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')", Severity: SeverityError, Rule: RuleMetadataViolation, Kind: "batch/v1/Job", Path: "/metadata/generateName", Actual: "\"Migration-\""},
+			},
+		},
+		{
+			name:     "missing name",
+			resource: testResource("v1", "ConfigMap", map[string]interface{}{"metadata": map[string]interface{}{"namespace": "example"}}),
+			expected: []ValidationResult{
+				{Message: "metadata.name: Required value: name or generateName is required", Severity: SeverityError, Rule: RuleMetadataViolation, Namespace: "example", Kind: "v1/ConfigMap", Path: "/metadata/name"},
+			},
+		},
+		{
+			name:     "labels and annotations",
+			resource: testResource("v1", "ConfigMap", map[string]interface{}{"metadata": map[string]interface{}{"name": "settings", "labels": map[string]interface{}{"app.kubernetes.io/version": strings.Repeat("1", 64)}, "annotations": map[string]interface{}{"example.io/a/b": "value"}}}),
+			expected: []ValidationResult{
+				{Message: "metadata.labels: Invalid value: \"" + strings.Repeat("1", 64) + "\": must be no more than 63 characters", Severity: SeverityError, Rule: RuleMetadataViolation, Name: "settings", Kind: "v1/ConfigMap", Path: "/metadata/labels", Actual: "\"" + strings.Repeat("1", 64) + "\""},
+				{Message: "metadata.annotations: Invalid value: \"example.io/a/b\": a qualified name must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]') with an optional DNS subdomain prefix and '/' (e.g. 'example.com/MyName')", Severity: SeverityError, Rule: RuleMetadataViolation, Name: "settings", Kind: "v1/ConfigMap", Path: "/metadata/annotations", Actual: "\"example.io/a/b\""},
+			},
+		},
+		{
+			name:     "total size of the annotations",
+			resource: testResource("v1", "ConfigMap", map[string]interface{}{"metadata": map[string]interface{}{"name": "settings", "annotations": map[string]interface{}{"example.io/large": strings.Repeat("a", 256*1024)}}}),
+			expected: []ValidationResult{
+				{Message: "metadata.annotations: Too long: must have at most 262144 bytes", Severity: SeverityError, Rule: RuleMetadataViolation, Name: "settings", Kind: "v1/ConfigMap", Path: "/metadata/annotations"},
+			},
+		},
+		{
+			name:     "suppressed",
+			resource: testResource("v1", "ConfigMap", map[string]interface{}{"metadata": map[string]interface{}{"name": "Settings", "annotations": map[string]interface{}{IgnoreAnnotation: "metadata"}}}),
+			expected: []ValidationResult{
+				{Message: "metadata.name: Invalid value: \"Settings\": a DNS-1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')", Severity: SeveritySuppressed, Rule: RuleMetadataViolation, Name: "Settings", Kind: "v1/ConfigMap", Path: "/metadata/name", Actual: "\"Settings\""},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, NewMetadataValidator().Validate(test.resource))
+		})
+	}
+}
+
+func TestFieldPathPointer(t *testing.T) {
+	assert.Equal(t, "/metadata/name", fieldPathPointer("metadata.name"))
+	assert.Equal(t, "/metadata/labels/app.kubernetes.io~1name", fieldPathPointer("metadata.labels[app.kubernetes.io/name]"))
+	assert.Equal(t, "/spec/containers/0/name", fieldPathPointer("spec.containers[0].name"))
+}
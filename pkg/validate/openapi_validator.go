@@ -1,8 +1,10 @@
 package validate
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/fllaca/scheriff/pkg/kubernetes"
 	"github.com/fllaca/scheriff/pkg/utils"
@@ -28,11 +30,48 @@ func (extPropsGroupVersionKind extPropsGroupVersionKind) String() string {
 // OpenApiValidator validates Kubernetes manifests using OpenApi schemas
 type OpenApiValidator struct {
 	schemaCache map[string]*openapi3.Schema
+	// components holds the named schemas of the specs, referenced by the schemas in schemaCache
+	components map[string]*openapi3.SchemaRef
+	// verbose includes the details of the schema violations (failing schema and offending value) in the validation messages
+	verbose bool
+	// kubernetesVersion is the version of the schemas (ie: "v1.17.0"), if known
+	kubernetesVersion string
+	// deprecations holds the deprecation notices of the kinds deprecated in the schemas
+	deprecations map[string]string
+	// checkDeprecations reports the resources using deprecated or removed API versions
+	checkDeprecations bool
+	// namespaced tells whether each kind is namespaced, for the kinds whose scope is known
+	namespaced map[string]bool
+	// requireNamespace reports the namespaced resources without namespace
+	requireNamespace bool
 }
 
-func NewOpenApi2Validator(openApi2SpecsBytes []byte) (*OpenApiValidator, error) {
-	// TODO: set to false when using verbose output
-	openapi3.SchemaErrorDetailsDisabled = true
+// OpenApiValidatorOption sets optional behaviour of an OpenApiValidator
+type OpenApiValidatorOption func(*OpenApiValidator)
+
+// WithVerboseErrors makes the validator include the failing schema fragment and the offending value in the messages of schema violations
+func WithVerboseErrors(verbose bool) OpenApiValidatorOption {
+	return func(oeValidator *OpenApiValidator) {
+		oeValidator.verbose = verbose
+	}
+}
+
+// WithDeprecationChecks makes the validator report the resources whose API version is deprecated, either in the schemas
+// or in the Kubernetes deprecation guide, as well as the ones whose API version has been removed
+func WithDeprecationChecks(checkDeprecations bool) OpenApiValidatorOption {
+	return func(oeValidator *OpenApiValidator) {
+		oeValidator.checkDeprecations = checkDeprecations
+	}
+}
+
+// WithRequiredNamespace makes the validator report the resources of namespaced kinds without namespace
+func WithRequiredNamespace(requireNamespace bool) OpenApiValidatorOption {
+	return func(oeValidator *OpenApiValidator) {
+		oeValidator.requireNamespace = requireNamespace
+	}
+}
+
+func NewOpenApi2Validator(openApi2SpecsBytes []byte, options ...OpenApiValidatorOption) (*OpenApiValidator, error) {
 	swagger2 := &openapi2.Swagger{}
 
 	err := json.Unmarshal(openApi2SpecsBytes, swagger2)
@@ -40,13 +79,16 @@ func NewOpenApi2Validator(openApi2SpecsBytes []byte) (*OpenApiValidator, error)
 		return nil, err
 	}
 	// In kubernetes API specs this field is specifed as "type: string", although integers are also accepted
-	swagger2.Definitions["io.k8s.apimachinery.pkg.util.intstr.IntOrString"] = &openapi3.SchemaRef{
-		Value: openapi3.NewOneOfSchema(
-			openapi3.NewStringSchema(),
-			openapi3.NewInt32Schema()),
+	swagger2.Definitions[intOrStringSchemaName] = intOrStringSchema()
+	// and so are numbers in quantities
+	if _, ok := swagger2.Definitions[quantitySchemaName]; ok {
+		swagger2.Definitions[quantitySchemaName] = quantitySchema()
 	}
 
 	swagger3, err := openapi2conv.ToV3Swagger(swagger2)
+	if err != nil {
+		return nil, err
+	}
 	/* Alternative: add missing definitions after converting to openapi3
 	swagger3.Components.Schemas["io.k8s.apimachinery.pkg.util.intstr.IntOrString"] = &openapi3.SchemaRef{
 		Value: openapi3.NewOneOfSchema(
@@ -72,9 +114,123 @@ func NewOpenApi2Validator(openApi2SpecsBytes []byte) (*OpenApiValidator, error)
 		return nil, err
 	}
 
-	return &OpenApiValidator{
-		schemaCache: schemaCache,
-	}, nil
+	oeValidator := newOpenApiValidator(schemaCache, swagger2.Info.Version, options)
+	oeValidator.components = swagger3.Components.Schemas
+	oeValidator.namespaced = kindScopes(swagger2.Paths)
+	return oeValidator, nil
+}
+
+// NewOpenApi3Validator builds an OpenApiValidator from Kubernetes OpenAPI V3 documents, like the ones published per group-version
+// in the "/openapi/v3/apis/<group>/<version>" endpoints of the cluster. The schemas of all the documents are merged.
+func NewOpenApi3Validator(openApi3SpecsBytes [][]byte, options ...OpenApiValidatorOption) (*OpenApiValidator, error) {
+	schemaCache := make(map[string]*openapi3.Schema)
+	components := make(map[string]*openapi3.SchemaRef)
+	namespaced := make(map[string]bool)
+	kubernetesVersion := ""
+	for _, specsBytes := range openApi3SpecsBytes {
+		swagger3 := &openapi3.Swagger{}
+		err := json.Unmarshal(specsBytes, swagger3)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(swagger3.OpenAPI, "3.") {
+			return nil, fmt.Errorf("Not an OpenAPI V3 document, found version '%s'", swagger3.OpenAPI)
+		}
+		if kubernetesVersion == "" && swagger3.Info != nil {
+			kubernetesVersion = swagger3.Info.Version
+		}
+		if _, ok := swagger3.Components.Schemas[intOrStringSchemaName]; ok {
+			swagger3.Components.Schemas[intOrStringSchemaName] = intOrStringSchema()
+		}
+		if _, ok := swagger3.Components.Schemas[quantitySchemaName]; ok {
+			swagger3.Components.Schemas[quantitySchemaName] = quantitySchema()
+		}
+		for kind, kindNamespaced := range openApi3KindScopes(swagger3.Paths) {
+			namespaced[kind] = namespaced[kind] || kindNamespaced
+		}
+		// only the schemas (and the scopes of the kinds) are needed for validation, paths would just slow down resolving the references
+		swagger3.Paths = nil
+		err = openapi3.NewSwaggerLoader().ResolveRefsIn(swagger3, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		adaptSpecsToKubernetesValidation(swagger3)
+
+		documentSchemaCache, err := buildSchemaCache(swagger3)
+		if err != nil {
+			return nil, err
+		}
+		for kind, schema := range documentSchemaCache {
+			schemaCache[kind] = schema
+		}
+		for name, component := range swagger3.Components.Schemas {
+			components[name] = component
+		}
+	}
+	oeValidator := newOpenApiValidator(schemaCache, kubernetesVersion, options)
+	oeValidator.components = components
+	oeValidator.namespaced = namespaced
+	return oeValidator, nil
+}
+
+// IsOpenApi3 tells if the given specs are an OpenAPI V3 document (as opposed to an OpenAPI V2 "swagger" one).
+// Only the top-level keys are read, up to the "openapi" or "swagger" version, which are usually the first ones.
+func IsOpenApi3(specsBytes []byte) bool {
+	decoder := json.NewDecoder(bytes.NewReader(specsBytes))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return false
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		switch key {
+		case "openapi":
+			var version string
+			return decoder.Decode(&version) == nil && strings.HasPrefix(version, "3.")
+		case "swagger":
+			return false
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+func newOpenApiValidator(schemaCache map[string]*openapi3.Schema, kubernetesVersion string, options []OpenApiValidatorOption) *OpenApiValidator {
+	oeValidator := &OpenApiValidator{
+		schemaCache:       schemaCache,
+		kubernetesVersion: kubernetesVersion,
+		deprecations:      make(map[string]string),
+		namespaced:        make(map[string]bool),
+	}
+	schemas := make([]*openapi3.Schema, 0, len(schemaCache))
+	for kind, schema := range schemaCache {
+		if notice := deprecationNotice(kind, schema); notice != "" {
+			oeValidator.deprecations[kind] = notice
+		}
+		schemas = append(schemas, schema)
+	}
+	precompilePatterns(schemas...)
+	for _, option := range options {
+		option(oeValidator)
+	}
+	return oeValidator
+}
+
+const intOrStringSchemaName = "io.k8s.apimachinery.pkg.util.intstr.IntOrString"
+
+// intOrStringSchema accepts both strings and integers, as Kubernetes does for IntOrString fields
+func intOrStringSchema() *openapi3.SchemaRef {
+	return &openapi3.SchemaRef{
+		Value: openapi3.NewOneOfSchema(
+			openapi3.NewStringSchema().WithFormat("int-or-string"),
+			openapi3.NewInt32Schema()),
+	}
 }
 
 // adaptSpecsToKubernetesValidation adjusts the OpenAPI specifications to reflect the behaviour of Kubernetes when validating resources.
@@ -99,7 +255,19 @@ func isOptionalProperty(schema openapi3.Schema, property string) bool {
 	return utils.StringSliceIndexOf(schema.Required, property) < 0
 }
 
-func (oeValidator OpenApiValidator) Validate(input map[string]interface{}) ValidationResult {
+// Validate validates a resource against the schema of its kind. It's safe for concurrent use once all the schemas (ie: CRDs) are loaded.
+// The findings of the rules listed in the IgnoreAnnotation of the resource are suppressed.
+// HasKind tells if the schemas define the given kind (by "group/version/kind"), including the kinds of the CRDs
+func (oeValidator OpenApiValidator) HasKind(apiVersionKind string) bool {
+	_, ok := oeValidator.schemaCache[apiVersionKind]
+	return ok
+}
+
+func (oeValidator OpenApiValidator) Validate(input map[string]interface{}) []ValidationResult {
+	return suppressFindings(oeValidator.validate(input), annotationSuppressedRules(input))
+}
+
+func (oeValidator OpenApiValidator) validate(input map[string]interface{}) []ValidationResult {
 
 	kind := kubernetes.GetApiVersionKind(input)
 	name := kubernetes.GetName(input)
@@ -112,23 +280,53 @@ func (oeValidator OpenApiValidator) Validate(input map[string]interface{}) Valid
 		Namespace: namespace,
 	}
 
+	results := make([]ValidationResult, 0)
+	if oeValidator.checkDeprecations {
+		if deprecationResult := oeValidator.checkDeprecation(result, schema != nil); deprecationResult != nil {
+			results = append(results, *deprecationResult)
+		}
+	}
+
 	if schema == nil {
+		if len(results) > 0 {
+			return results
+		}
 		result.Message = fmt.Sprintf("Kind '%s' not found in schema", kind)
 		result.Severity = SeverityWarning
-		return result
+		result.Rule = RuleUnknownKind
+		return []ValidationResult{result}
 	}
 
-	err := schema.VisitJSON(input)
+	if scopeResult := oeValidator.checkScope(result); scopeResult != nil {
+		results = append(results, *scopeResult)
+	}
 
-	if err != nil {
-		result.Message = err.Error()
-		result.Severity = SeverityError
-		return result
+	violations := collectViolations(schema, input, []string{})
+
+	if len(violations) > 0 {
+		for _, violation := range violations {
+			violationResult := result
+			violationResult.Message = violation.message()
+			if oeValidator.verbose {
+				violationResult.Message += violation.details()
+			}
+			violationResult.Severity = SeverityError
+			violationResult.Rule = RuleSchemaViolation
+			violationResult.Path = jsonPointer(violation.path)
+			violationResult.Constraint = violation.err.SchemaField
+			violationResult.Expected = violation.expected()
+			violationResult.Actual = violation.actual()
+			results = append(results, violationResult)
+		}
+		return results
+	}
+	if len(results) > 0 {
+		return results
 	}
 
 	result.Message = "valid"
 	result.Severity = SeverityOK
-	return result
+	return []ValidationResult{result}
 }
 
 func buildSchemaCache(swagger3 *openapi3.Swagger) (map[string]*openapi3.Schema, error) {
@@ -186,8 +384,11 @@ func (oeValidator OpenApiValidator) AddCrdSchemas(crdResource kubernetes.Resourc
 				Version: version.Name,
 				Kind:    crdv1.Spec.Names.Kind,
 			}
+			precompilePatterns(schema)
 			oeValidator.schemaCache[kindDef.String()] = schema
+			oeValidator.namespaced[kindDef.String()] = crdv1.Spec.Scope != apiextensionsv1.ClusterScoped
 		}
+		return oeValidator.addCrdDeprecations(crdResource, crdv1.Spec.Group, crdv1.Spec.Names.Kind)
 	case crdv1beta1ApiVersionKind:
 		crdv1beta1 := apiextensionsv1beta1.CustomResourceDefinition{}
 		err := convertObject(crdResource, &crdv1beta1)
@@ -214,11 +415,39 @@ func (oeValidator OpenApiValidator) AddCrdSchemas(crdResource kubernetes.Resourc
 				Version: version.Name,
 				Kind:    crdv1beta1.Spec.Names.Kind,
 			}
+			precompilePatterns(schema)
 			oeValidator.schemaCache[kindDef.String()] = schema
+			oeValidator.namespaced[kindDef.String()] = crdv1beta1.Spec.Scope != apiextensionsv1beta1.ClusterScoped
 		}
+		return oeValidator.addCrdDeprecations(crdResource, crdv1beta1.Spec.Group, crdv1beta1.Spec.Names.Kind)
 	default:
 		return fmt.Errorf("Invalid CRD Kind: %s", apiVersionKind)
 	}
+}
+
+// addCrdDeprecations keeps the deprecation notices of the versions of a CustomResourceDefinition marked as deprecated
+func (oeValidator OpenApiValidator) addCrdDeprecations(crdResource kubernetes.Resource, group string, kind string) error {
+	deprecations := crdDeprecations{}
+	err := convertObject(crdResource, &deprecations)
+	if err != nil {
+		return err
+	}
+	for _, version := range deprecations.Spec.Versions {
+		kindDef := extPropsGroupVersionKind{
+			Group:   group,
+			Version: version.Name,
+			Kind:    kind,
+		}
+		switch {
+		case !version.Deprecated:
+			delete(oeValidator.deprecations, kindDef.String())
+		case version.DeprecationWarning != nil:
+			oeValidator.deprecations[kindDef.String()] = *version.DeprecationWarning
+		default:
+			// same warning returned by the Kubernetes API server
+			oeValidator.deprecations[kindDef.String()] = fmt.Sprintf("%s/%s %s is deprecated", group, version.Name, kind)
+		}
+	}
 	return nil
 }
 
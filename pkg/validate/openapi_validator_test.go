@@ -0,0 +1,70 @@
+package validate
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadTestSwagger(t *testing.T) []byte {
+	specsBytes, err := ioutil.ReadFile("testdata/swagger.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return specsBytes
+}
+
+// testResource builds a resource named "test" with the given top-level fields (ie: "spec"), where "metadata" replaces
+// the default metadata
+func testResource(apiVersion string, kind string, fields map[string]interface{}) map[string]interface{} {
+	resource := map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": "test"},
+	}
+	for key, value := range fields {
+		resource[key] = value
+	}
+	return resource
+}
+
+func TestOpenApiValidatorVerboseErrors(t *testing.T) {
+	specsBytes := loadTestSwagger(t)
+	configMap := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "test"},
+		"data":       map[string]interface{}{"key": float64(1)},
+	}
+
+	validator, err := NewOpenApi2Validator(specsBytes)
+	assert.NoError(t, err)
+	verboseValidator, err := NewOpenApi2Validator(specsBytes, WithVerboseErrors(true))
+	assert.NoError(t, err)
+
+	results := validator.Validate(configMap)
+	verboseResults := verboseValidator.Validate(configMap)
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, "Error at \"/data/key\":Field must be set to string or not be present", results[0].Message)
+	assert.Len(t, verboseResults, 1)
+	assert.Equal(t, "Error at \"/data/key\":Field must be set to string or not be present\nSchema:\n  {\n    \"type\": \"string\"\n  }\n\nValue:\n  1\n", verboseResults[0].Message)
+	assert.Equal(t, "/data/key", verboseResults[0].Path)
+	// the package-level setting of kin-openapi is left untouched
+	assert.False(t, openapi3.SchemaErrorDetailsDisabled)
+}
+
+func TestIsOpenApi3(t *testing.T) {
+	assert.False(t, IsOpenApi3(loadTestSwagger(t)))
+	assert.True(t, IsOpenApi3([]byte(`{"openapi": "3.0.0", "info": {"title": "Kubernetes", "version": "v1.24.0"}}`)))
+	assert.False(t, IsOpenApi3([]byte(`not json`)))
+	assert.True(t, IsOpenApi3([]byte(`{"info": {"title": "Kubernetes", "version": "v1.24.0"}, "openapi": "3.0.0"}`)))
+	assert.False(t, IsOpenApi3([]byte(`{"swagger": "2.0", "paths": {}}`)))
+}
+
+func TestNewOpenApi3ValidatorInvalidVersion(t *testing.T) {
+	_, err := NewOpenApi3Validator([][]byte{loadTestSwagger(t)})
+	assert.EqualError(t, err, "Not an OpenAPI V3 document, found version ''")
+}
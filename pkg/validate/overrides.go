@@ -0,0 +1,71 @@
+package validate
+
+import (
+	"github.com/fllaca/scheriff/pkg/fs"
+	"github.com/fllaca/scheriff/pkg/kubernetes"
+)
+
+// RuleOverride changes the severity of the findings of a rule for the kinds that match a pattern
+type RuleOverride struct {
+	// Kind is a glob pattern (see fs.MatchGlob) of the kinds the override applies to (ie: "monitoring.coreos.com/v1/*" or "**/Secret")
+	Kind string
+	// Rule is the rule whose findings are overridden, or empty for all the rules
+	Rule string
+	// Severity is the new severity of the findings, SeverityOK ignores them
+	Severity Severity
+}
+
+// overridesValidator changes the severity of the findings of another ResourceValidator
+type overridesValidator struct {
+	resourceValidator ResourceValidator
+	overrides         []RuleOverride
+}
+
+// NewOverridesValidator applies the overrides to the findings of 'resourceValidator'. When several overrides match a finding,
+// the last one wins. If all the findings of a resource are ignored, the resource is reported as valid.
+func NewOverridesValidator(resourceValidator ResourceValidator, overrides []RuleOverride) ResourceValidator {
+	if len(overrides) == 0 {
+		return resourceValidator
+	}
+	return overridesValidator{
+		resourceValidator: resourceValidator,
+		overrides:         overrides,
+	}
+}
+
+func (validator overridesValidator) Validate(resource map[string]interface{}) []ValidationResult {
+	results := make([]ValidationResult, 0)
+	for _, result := range validator.resourceValidator.Validate(resource) {
+		if !isFinding(result) {
+			results = append(results, result)
+			continue
+		}
+		result = applyOverrides(result, validator.overrides)
+		if isFinding(result) {
+			results = append(results, result)
+		}
+	}
+	if len(results) == 0 {
+		results = append(results, ValidationResult{
+			Message:   "valid",
+			Severity:  SeverityOK,
+			Name:      kubernetes.GetName(resource),
+			Namespace: kubernetes.GetNamespace(resource),
+			Kind:      kubernetes.GetApiVersionKind(resource),
+		})
+	}
+	return results
+}
+
+// applyOverrides sets the severity of the last override matching a finding, other results are returned as they are
+func applyOverrides(result ValidationResult, overrides []RuleOverride) ValidationResult {
+	if !isFinding(result) {
+		return result
+	}
+	for _, override := range overrides {
+		if (override.Rule == "" || override.Rule == result.Rule) && fs.MatchGlob(override.Kind, result.Kind) {
+			result.Severity = override.Severity
+		}
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resultsValidator is a ResourceValidator that returns the same results for every resource
+type resultsValidator []ValidationResult
+
+func (validator resultsValidator) Validate(resource map[string]interface{}) []ValidationResult {
+	return append([]ValidationResult{}, validator...)
+}
+
+func TestOverridesValidator(t *testing.T) {
+	unknownKind := ValidationResult{Message: "Kind 'monitoring.coreos.com/v1/ServiceMonitor' not found in schema", Severity: SeverityWarning, Rule: RuleUnknownKind, Name: "test", Kind: "monitoring.coreos.com/v1/ServiceMonitor"}
+	violation := ValidationResult{Message: "Property 'foo' is unsupported", Severity: SeverityError, Rule: RuleSchemaViolation, Name: "test", Kind: "monitoring.coreos.com/v1/ServiceMonitor"}
+
+	tests := []struct {
+		name      string
+		overrides []RuleOverride
+		expected  []ValidationResult
+	}{
+		{
+			name:     "no overrides",
+			expected: []ValidationResult{unknownKind, violation},
+		},
+		{
+			name:      "other kinds",
+			overrides: []RuleOverride{{Kind: "**/Secret", Severity: SeverityOK}},
+			expected:  []ValidationResult{unknownKind, violation},
+		},
+		{
+			name:      "ignored rule",
+			overrides: []RuleOverride{{Kind: "monitoring.coreos.com/*/*", Rule: RuleUnknownKind, Severity: SeverityOK}},
+			expected:  []ValidationResult{violation},
+		},
+		{
+			name:      "changed severity, the last override wins",
+			overrides: []RuleOverride{{Kind: "**", Rule: RuleSchemaViolation, Severity: SeverityOK}, {Kind: "**/ServiceMonitor", Rule: RuleSchemaViolation, Severity: SeverityWarning}},
+			expected:  []ValidationResult{unknownKind, {Message: "Property 'foo' is unsupported", Severity: SeverityWarning, Rule: RuleSchemaViolation, Name: "test", Kind: "monitoring.coreos.com/v1/ServiceMonitor"}},
+		},
+		{
+			name:      "all findings ignored",
+			overrides: []RuleOverride{{Kind: "**/ServiceMonitor", Severity: SeverityOK}},
+			expected:  []ValidationResult{{Message: "valid", Severity: SeverityOK, Name: "test", Kind: "monitoring.coreos.com/v1/ServiceMonitor"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validator := NewOverridesValidator(resultsValidator{unknownKind, violation}, test.overrides)
+			assert.Equal(t, test.expected, validator.Validate(testResource("monitoring.coreos.com/v1", "ServiceMonitor", nil)))
+		})
+	}
+}
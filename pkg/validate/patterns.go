@@ -0,0 +1,59 @@
+package validate
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// precompilePatterns compiles the regular expressions of the "pattern" and "format" of every schema reachable from the given ones.
+// openapi3.Schema.VisitJSON compiles them lazily and caches them in the schema itself, which is a data race when validating
+// resources concurrently. Compiling them while loading the schemas makes the later validations read-only.
+func precompilePatterns(schemas ...*openapi3.Schema) {
+	visited := make(map[*openapi3.Schema]bool)
+	for _, schema := range schemas {
+		precompileSchemaPatterns(schema, visited)
+	}
+}
+
+func precompileSchemaPatterns(schema *openapi3.Schema, visited map[*openapi3.Schema]bool) {
+	if schema == nil || visited[schema] {
+		return
+	}
+	visited[schema] = true
+
+	if schema.Pattern != "" || schema.Format != "" {
+		compilePattern(schema)
+	}
+
+	for _, property := range schema.Properties {
+		precompileRefPatterns(property, visited)
+	}
+	precompileRefPatterns(schema.AdditionalProperties, visited)
+	precompileRefPatterns(schema.Items, visited)
+	precompileRefPatterns(schema.Not, visited)
+	for _, schemaRefs := range [][]*openapi3.SchemaRef{schema.AllOf, schema.OneOf, schema.AnyOf} {
+		for _, schemaRef := range schemaRefs {
+			precompileRefPatterns(schemaRef, visited)
+		}
+	}
+}
+
+func precompileRefPatterns(schemaRef *openapi3.SchemaRef, visited map[*openapi3.Schema]bool) {
+	if schemaRef != nil {
+		precompileSchemaPatterns(schemaRef.Value, visited)
+	}
+}
+
+// compilePattern makes VisitJSON compile (and cache) the pattern of the schema, by temporarily disabling the
+// constraints that are checked before the pattern (so they can't make the validation of an empty string fail earlier)
+func compilePattern(schema *openapi3.Schema) {
+	schemaType, enum, not, oneOf, anyOf, allOf := schema.Type, schema.Enum, schema.Not, schema.OneOf, schema.AnyOf, schema.AllOf
+	minLength, maxLength := schema.MinLength, schema.MaxLength
+	schema.Type, schema.Enum, schema.Not, schema.OneOf, schema.AnyOf, schema.AllOf = "", nil, nil, nil, nil, nil
+	schema.MinLength, schema.MaxLength = 0, nil
+
+	// the result is irrelevant, only the side effect of caching the compiled pattern is needed
+	_ = schema.VisitJSON("")
+
+	schema.Type, schema.Enum, schema.Not, schema.OneOf, schema.AnyOf, schema.AllOf = schemaType, enum, not, oneOf, anyOf, allOf
+	schema.MinLength, schema.MaxLength = minLength, maxLength
+}
@@ -0,0 +1,49 @@
+package validate
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrecompilePatterns(t *testing.T) {
+	maxLength := uint64(8)
+	name := &openapi3.Schema{
+		Type:      "string",
+		Pattern:   "^[a-z]+$",
+		MinLength: 3,
+		MaxLength: &maxLength,
+	}
+	schema := openapi3.NewObjectSchema().WithProperty("name", name)
+
+	precompilePatterns(schema, schema)
+
+	// the constraints disabled to compile the pattern are restored
+	assert.Equal(t, "string", name.Type)
+	assert.Equal(t, uint64(3), name.MinLength)
+	assert.Equal(t, &maxLength, name.MaxLength)
+
+	values := []string{"valid", "INVALID", "ab", "waytoolong"}
+	expected := []string{"", "pattern", "minLength", "maxLength"}
+	// the schema is only read when validating concurrently (run with -race)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j, value := range values {
+				violations := collectViolations(schema, map[string]interface{}{"name": value}, []string{})
+				if expected[j] == "" {
+					assert.Empty(t, violations)
+					continue
+				}
+				if assert.Len(t, violations, 1) {
+					assert.Equal(t, expected[j], violations[0].err.SchemaField)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
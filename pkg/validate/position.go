@@ -0,0 +1,93 @@
+package validate
+
+import (
+	"strconv"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// jsonPointer builds a JSON pointer (RFC 6901) from the tokens of a path
+func jsonPointer(tokens []string) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	escapedTokens := make([]string, len(tokens))
+	for i, token := range tokens {
+		escapedTokens[i] = escaper.Replace(token)
+	}
+	return "/" + strings.Join(escapedTokens, "/")
+}
+
+// jsonPointerTokens splits a JSON pointer (RFC 6901) into the tokens of its path
+func jsonPointerTokens(pointer string) []string {
+	if pointer == "" {
+		return []string{}
+	}
+	unescaper := strings.NewReplacer("~1", "/", "~0", "~")
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = unescaper.Replace(token)
+	}
+	return tokens
+}
+
+// findPosition returns the line and column of the field at the given JSON pointer, given the root node of its document.
+// If the field doesn't exist in the document, the position of its closest existing ancestor is returned.
+// Fields in mappings are located by their key, so the position points to the line where the field is declared.
+func findPosition(root *yamlv3.Node, pointer string) (int, int) {
+	if root == nil {
+		return 0, 0
+	}
+	node := root
+	position := node
+	for _, token := range jsonPointerTokens(pointer) {
+		if node.Kind == yamlv3.AliasNode {
+			node = node.Alias
+		}
+		key, value := childNode(node, token)
+		if value == nil {
+			break
+		}
+		node = value
+		position = value
+		if key != nil {
+			position = key
+		}
+	}
+	return position.Line, position.Column
+}
+
+// findNode returns the node of the field at the given JSON pointer, or nil if it doesn't exist in the document
+func findNode(root *yamlv3.Node, pointer string) *yamlv3.Node {
+	node := root
+	for _, token := range jsonPointerTokens(pointer) {
+		if node == nil {
+			return nil
+		}
+		if node.Kind == yamlv3.AliasNode {
+			node = node.Alias
+		}
+		_, node = childNode(node, token)
+	}
+	return node
+}
+
+// childNode returns the key (for mappings) and value nodes of a mapping or sequence at the given path token.
+func childNode(node *yamlv3.Node, token string) (*yamlv3.Node, *yamlv3.Node) {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == token {
+				return node.Content[i], node.Content[i+1]
+			}
+		}
+	case yamlv3.SequenceNode:
+		index, err := strconv.Atoi(token)
+		if err == nil && index >= 0 && index < len(node.Content) {
+			return nil, node.Content[index]
+		}
+	}
+	return nil, nil
+}
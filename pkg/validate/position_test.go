@@ -0,0 +1,60 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const positionTestDocument = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test
+  labels:
+    app.kubernetes.io/name: test
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx
+        ports:
+        - containerPort: 80
+`
+
+func TestFindPosition(t *testing.T) {
+	tests := []struct {
+		name           string
+		pointer        string
+		expectedLine   int
+		expectedColumn int
+	}{
+		{name: "document root", pointer: "", expectedLine: 1, expectedColumn: 1},
+		{name: "mapping field", pointer: "/metadata/name", expectedLine: 4, expectedColumn: 3},
+		{name: "escaped key", pointer: "/metadata/labels/app.kubernetes.io~1name", expectedLine: 6, expectedColumn: 5},
+		{name: "sequence item", pointer: "/spec/template/spec/containers/0", expectedLine: 11, expectedColumn: 9},
+		{name: "nested sequence field", pointer: "/spec/template/spec/containers/0/ports/0/containerPort", expectedLine: 13, expectedColumn: 11},
+		{name: "missing field falls back to its parent", pointer: "/spec/template/spec/containers/0/name", expectedLine: 11, expectedColumn: 9},
+		{name: "index out of range falls back to the sequence", pointer: "/spec/template/spec/containers/1", expectedLine: 10, expectedColumn: 7},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document := &yamlv3.Node{}
+			err := yamlv3.Unmarshal([]byte(positionTestDocument), document)
+			assert.NoError(t, err)
+
+			line, column := findPosition(document.Content[0], test.pointer)
+			assert.Equal(t, test.expectedLine, line)
+			assert.Equal(t, test.expectedColumn, column)
+		})
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	tokens := []string{"metadata", "annotations", "example.io/some~key"}
+	pointer := jsonPointer(tokens)
+
+	assert.Equal(t, "/metadata/annotations/example.io~1some~0key", pointer)
+	assert.Equal(t, tokens, jsonPointerTokens(pointer))
+}
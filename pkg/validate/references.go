@@ -0,0 +1,215 @@
+package validate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fllaca/scheriff/pkg/fs"
+	"github.com/fllaca/scheriff/pkg/kubernetes"
+)
+
+// podSpecPaths holds the path to the pod spec of the kinds (by "group/kind") that define pods
+var podSpecPaths = map[string][]string{
+	"/Pod":                   {"spec"},
+	"/ReplicationController": {"spec", "template", "spec"},
+	"apps/Deployment":        {"spec", "template", "spec"},
+	"apps/StatefulSet":       {"spec", "template", "spec"},
+	"apps/DaemonSet":         {"spec", "template", "spec"},
+	"apps/ReplicaSet":        {"spec", "template", "spec"},
+	"extensions/Deployment":  {"spec", "template", "spec"},
+	"extensions/DaemonSet":   {"spec", "template", "spec"},
+	"extensions/ReplicaSet":  {"spec", "template", "spec"},
+	"batch/Job":              {"spec", "template", "spec"},
+	"batch/CronJob":          {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// builtinReferences are the objects Kubernetes creates in every namespace, so their references are always resolved
+var builtinReferences = []string{"ServiceAccount/default", "ConfigMap/kube-root-ca.crt"}
+
+// reference is a reference from a resource to an object of the core API group in its namespace
+type reference struct {
+	kind string
+	name string
+	// path to the name of the referenced object in the resource
+	path []string
+	// port is the port of a Service reference (a number or a port name), if any
+	port     interface{}
+	portPath []string
+}
+
+// ReferencesValidator checks that the objects referenced by the resources are defined in the validated resources: the
+// ConfigMaps, Secrets, PersistentVolumeClaims and ServiceAccounts used by pods and pod templates, and the Services (and
+// their ports) used as Ingress backends. Optional references (ie: 'optional: true' ConfigMaps) aren't checked.
+type ReferencesValidator struct {
+	allowed          []string
+	defaultNamespace string
+}
+
+// NewReferencesValidator creates a ReferencesValidator. The references to the objects that match any of the 'allowed' glob
+// patterns of "Kind/name" (ie: "Secret/registry-*") aren't checked, as they are managed apart from the validated resources.
+// The resources without namespace belong to 'defaultNamespace'.
+func NewReferencesValidator(allowed []string, defaultNamespace string) ReferencesValidator {
+	return ReferencesValidator{
+		allowed:          append(append([]string{}, builtinReferences...), allowed...),
+		defaultNamespace: defaultNamespace,
+	}
+}
+
+func (validator ReferencesValidator) Validate(resources []LocatedResource) []ValidationResult {
+	index := make(map[string]map[string]interface{})
+	for _, located := range resources {
+		// the referenced kinds belong to the core API group
+		if coreKind := strings.TrimPrefix(groupKind(located.Resource), "/"); !strings.Contains(coreKind, "/") {
+			index[referenceKey(coreKind, resourceNamespace(located.Resource, validator.defaultNamespace), kubernetes.GetName(located.Resource))] = located.Resource
+		}
+	}
+
+	results := make([]ValidationResult, 0)
+	for _, located := range resources {
+		namespace := resourceNamespace(located.Resource, validator.defaultNamespace)
+		for _, ref := range resourceReferences(located.Resource) {
+			if validator.isAllowed(ref) {
+				continue
+			}
+			target, ok := index[referenceKey(ref.kind, namespace, ref.name)]
+			if !ok {
+				results = append(results, located.finding(SeverityError, RuleMissingReference, ref.path,
+					fmt.Sprintf("%s '%s' not found in the validated resources", ref.kind, ref.name)))
+				continue
+			}
+			if ref.port != nil && !hasServicePort(target, ref.port) {
+				results = append(results, located.finding(SeverityError, RuleMissingReference, ref.portPath,
+					fmt.Sprintf("Service '%s' has no port %v", ref.name, ref.port)))
+			}
+		}
+	}
+	return results
+}
+
+func (validator ReferencesValidator) isAllowed(ref reference) bool {
+	for _, pattern := range validator.allowed {
+		if fs.MatchGlob(pattern, ref.kind+"/"+ref.name) {
+			return true
+		}
+	}
+	return false
+}
+
+func referenceKey(kind string, namespace string, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// resourceReferences returns the references of a resource to other objects
+func resourceReferences(resource map[string]interface{}) []reference {
+	switch groupKind := groupKind(resource); groupKind {
+	case "networking.k8s.io/Ingress", "extensions/Ingress":
+		return ingressReferences(resource)
+	default:
+		if path, ok := podSpecPaths[groupKind]; ok {
+			return podSpecReferences(kubernetes.GetField(resource, path...), path)
+		}
+	}
+	return nil
+}
+
+func podSpecReferences(podSpec interface{}, path []string) []reference {
+	references := make([]reference, 0)
+	addReference := func(kind string, value interface{}, optional interface{}, tokens ...string) {
+		name, ok := value.(string)
+		if !ok || name == "" || optional == true {
+			return
+		}
+		references = append(references, reference{kind: kind, name: name, path: appendPath(path, tokens...)})
+	}
+
+	for _, field := range []string{"serviceAccountName", "serviceAccount"} {
+		if name := kubernetes.GetField(podSpec, field); name != nil {
+			addReference("ServiceAccount", name, nil, field)
+			break
+		}
+	}
+	forEachItem(kubernetes.GetField(podSpec, "imagePullSecrets"), func(i string, secret interface{}) {
+		addReference("Secret", kubernetes.GetField(secret, "name"), nil, "imagePullSecrets", i, "name")
+	})
+	for _, containers := range []string{"initContainers", "containers"} {
+		forEachItem(kubernetes.GetField(podSpec, containers), func(i string, container interface{}) {
+			forEachItem(kubernetes.GetField(container, "envFrom"), func(j string, envFrom interface{}) {
+				configMap := kubernetes.GetField(envFrom, "configMapRef")
+				addReference("ConfigMap", kubernetes.GetField(configMap, "name"), kubernetes.GetField(configMap, "optional"), containers, i, "envFrom", j, "configMapRef", "name")
+				secret := kubernetes.GetField(envFrom, "secretRef")
+				addReference("Secret", kubernetes.GetField(secret, "name"), kubernetes.GetField(secret, "optional"), containers, i, "envFrom", j, "secretRef", "name")
+			})
+		})
+	}
+	forEachItem(kubernetes.GetField(podSpec, "volumes"), func(i string, volume interface{}) {
+		configMap := kubernetes.GetField(volume, "configMap")
+		addReference("ConfigMap", kubernetes.GetField(configMap, "name"), kubernetes.GetField(configMap, "optional"), "volumes", i, "configMap", "name")
+		secret := kubernetes.GetField(volume, "secret")
+		addReference("Secret", kubernetes.GetField(secret, "secretName"), kubernetes.GetField(secret, "optional"), "volumes", i, "secret", "secretName")
+		claim := kubernetes.GetField(volume, "persistentVolumeClaim")
+		addReference("PersistentVolumeClaim", kubernetes.GetField(claim, "claimName"), nil, "volumes", i, "persistentVolumeClaim", "claimName")
+	})
+	return references
+}
+
+// ingressReferences returns the Services used as backends by an Ingress, for both the networking.k8s.io/v1 backends
+// (service.name and service.port) and the older ones (serviceName and servicePort)
+func ingressReferences(ingress map[string]interface{}) []reference {
+	references := make([]reference, 0)
+	addBackend := func(backend interface{}, path []string) {
+		if service := kubernetes.GetField(backend, "service"); service != nil {
+			name, _ := kubernetes.GetField(service, "name").(string)
+			ref := reference{kind: "Service", name: name, path: appendPath(path, "service", "name")}
+			for _, field := range []string{"number", "name"} {
+				if port := kubernetes.GetField(service, "port", field); port != nil {
+					ref.port, ref.portPath = port, appendPath(path, "service", "port", field)
+				}
+			}
+			if name != "" {
+				references = append(references, ref)
+			}
+			return
+		}
+		if name, _ := kubernetes.GetField(backend, "serviceName").(string); name != "" {
+			ref := reference{kind: "Service", name: name, path: appendPath(path, "serviceName")}
+			if port := kubernetes.GetField(backend, "servicePort"); port != nil {
+				ref.port, ref.portPath = port, appendPath(path, "servicePort")
+			}
+			references = append(references, ref)
+		}
+	}
+
+	for _, field := range []string{"defaultBackend", "backend"} {
+		addBackend(kubernetes.GetField(ingress, "spec", field), []string{"spec", field})
+	}
+	forEachItem(kubernetes.GetField(ingress, "spec", "rules"), func(i string, rule interface{}) {
+		forEachItem(kubernetes.GetField(rule, "http", "paths"), func(j string, path interface{}) {
+			addBackend(kubernetes.GetField(path, "backend"), []string{"spec", "rules", i, "http", "paths", j, "backend"})
+		})
+	})
+	return references
+}
+
+// hasServicePort tells if a Service has a port with the given number or name
+func hasServicePort(service map[string]interface{}, port interface{}) bool {
+	found := false
+	forEachItem(kubernetes.GetField(service, "spec", "ports"), func(_ string, servicePort interface{}) {
+		switch port := port.(type) {
+		case float64:
+			found = found || kubernetes.GetField(servicePort, "port") == port
+		case string:
+			number, err := strconv.Atoi(port)
+			found = found || kubernetes.GetField(servicePort, "name") == port || (err == nil && kubernetes.GetField(servicePort, "port") == float64(number))
+		}
+	})
+	return found
+}
+
+// forEachItem calls 'apply' with the index (as a path token) and the value of each item of a list, if the value is a list
+func forEachItem(value interface{}, apply func(index string, item interface{})) {
+	items, _ := value.([]interface{})
+	for i, item := range items {
+		apply(strconv.Itoa(i), item)
+	}
+}
@@ -0,0 +1,208 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const referencesTestManifests = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+  namespace: prod
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+spec:
+  ports:
+  - name: http
+    port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  template:
+    spec:
+      serviceAccountName: web
+      imagePullSecrets:
+      - name: registry-credentials
+      containers:
+      - name: web
+        envFrom:
+        - configMapRef:
+            name: app-config
+        - secretRef:
+            name: app-secrets
+        - secretRef:
+            name: optional-secrets
+            optional: true
+      volumes:
+      - name: config
+        configMap:
+          name: app-confg
+      - name: data
+        persistentVolumeClaim:
+          claimName: data
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+  namespace: prod
+spec:
+  rules:
+  - http:
+      paths:
+      - path: /
+        backend:
+          service:
+            name: web
+            port:
+              name: http
+      - path: /api
+        backend:
+          service:
+            name: web
+            port:
+              number: 8080
+      - path: /docs
+        backend:
+          service:
+            name: docs
+            port:
+              number: 80
+`
+
+func readTestResources(t *testing.T, manifests string) []LocatedResource {
+	return readSourceResources(t, "test.yaml", manifests)
+}
+
+// readSourceResources reads the resources of a test manifest as YamlFileValidator.ValidateResources does
+func readSourceResources(t *testing.T, source string, manifests string) []LocatedResource {
+	resources := make([]LocatedResource, 0)
+	err := decodeResources(source, strings.NewReader(manifests), nil, func(located LocatedResource) {
+		resources = append(resources, located)
+	}, func(ValidationResult) {})
+	assert.NoError(t, err)
+	return resources
+}
+
+func TestReferencesValidator(t *testing.T) {
+	resources := readTestResources(t, referencesTestManifests)
+
+	results := NewReferencesValidator([]string{"Secret/registry-*"}, "default").Validate(resources)
+
+	deployment := func(message string, path string, line int, column int) ValidationResult {
+		return ValidationResult{Message: message, Severity: SeverityError, Rule: RuleMissingReference, Name: "web", Namespace: "prod", Kind: "apps/v1/Deployment", Source: "test.yaml", Document: 2, Path: path, Line: line, Column: column}
+	}
+	ingress := func(message string, path string, line int, column int) ValidationResult {
+		return ValidationResult{Message: message, Severity: SeverityError, Rule: RuleMissingReference, Name: "web", Namespace: "prod", Kind: "networking.k8s.io/v1/Ingress", Source: "test.yaml", Document: 3, Path: path, Line: line, Column: column}
+	}
+	assert.Equal(t, []ValidationResult{
+		deployment("ServiceAccount 'web' not found in the validated resources", "/spec/template/spec/serviceAccountName", 25, 7),
+		deployment("Secret 'app-secrets' not found in the validated resources", "/spec/template/spec/containers/0/envFrom/1/secretRef/name", 34, 13),
+		deployment("ConfigMap 'app-confg' not found in the validated resources", "/spec/template/spec/volumes/0/configMap/name", 41, 11),
+		deployment("PersistentVolumeClaim 'data' not found in the validated resources", "/spec/template/spec/volumes/1/persistentVolumeClaim/claimName", 44, 11),
+		ingress("Service 'web' has no port 8080", "/spec/rules/0/http/paths/1/backend/service/port/number", 66, 15),
+		ingress("Service 'docs' not found in the validated resources", "/spec/rules/0/http/paths/2/backend/service/name", 70, 13),
+	}, results)
+}
+
+func TestReferencesValidatorNamespaces(t *testing.T) {
+	resources := readTestResources(t, `apiVersion: v1
+kind: Secret
+metadata:
+  name: app-secrets
+  namespace: dev
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+  namespace: prod
+spec:
+  serviceAccountName: default
+  containers:
+  - name: app
+    envFrom:
+    - secretRef:
+        name: app-secrets
+`)
+
+	results := NewReferencesValidator(nil, "default").Validate(resources)
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, "Secret 'app-secrets' not found in the validated resources", results[0].Message)
+	assert.Equal(t, "/spec/containers/0/envFrom/0/secretRef/name", results[0].Path)
+}
+
+func TestReferencesValidatorLegacyIngress(t *testing.T) {
+	resources := readTestResources(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - name: http
+    port: 80
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  backend:
+    serviceName: web
+    servicePort: http
+  rules:
+  - http:
+      paths:
+      - backend:
+          serviceName: web
+          servicePort: 80
+      - backend:
+          serviceName: web
+          servicePort: metrics
+`)
+
+	results := NewReferencesValidator(nil, "default").Validate(resources)
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, "Service 'web' has no port metrics", results[0].Message)
+	assert.Equal(t, "/spec/rules/0/http/paths/1/backend/servicePort", results[0].Path)
+}
+
+func TestReferencesValidatorSuppressions(t *testing.T) {
+	resources := readTestResources(t, `# scheriff:ignore missing
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  serviceAccountName: app
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: worker
+  annotations:
+    scheriff.io/ignore: missing-reference
+spec:
+  serviceAccountName: worker
+`)
+
+	results := NewReferencesValidator(nil, "default").Validate(resources)
+
+	assert.Len(t, results, 2)
+	for _, result := range results {
+		assert.Equal(t, SeveritySuppressed, result.Severity)
+	}
+}
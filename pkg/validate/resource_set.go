@@ -0,0 +1,122 @@
+package validate
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/fllaca/scheriff/pkg/kubernetes"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// SetValidator validates the resources of a run as a whole (ie: the references between them), once all of them are read
+type SetValidator interface {
+	// Validate returns the findings found in the resources, unlike ResourceValidator it doesn't return OK results
+	Validate(resources []LocatedResource) []ValidationResult
+}
+
+// LocatedResource is a resource along with the place it was read from, so that the findings of a SetValidator can be located
+type LocatedResource struct {
+	Resource map[string]interface{}
+	Source   string
+	Document int
+	Item     *int
+	node     *yamlv3.Node
+	// suppressed are the rules suppressed by an IgnoreComment above the document
+	suppressed suppressedRules
+}
+
+// decodeResources decodes the resources of a YAML (or JSON) stream as they are read, including the items of Lists (see
+// kubernetes.ListItems for 'knownKind'), and calls 'visit' with each of them, or 'parseError' with the finding of the
+// documents and items that can't be parsed. The error is only returned when the stream can't be read.
+func decodeResources(source string, reader io.Reader, knownKind func(string) bool, visit func(LocatedResource), parseError func(ValidationResult)) error {
+	decoder := kubernetes.NewYamlDecoder(reader)
+	for {
+		document, err := decoder.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if documentError, ok := err.(*kubernetes.DocumentError); ok {
+			parseError(ValidationResult{
+				Message:  fmt.Sprintf("Error parsing k8s resource from document %d: %s\n", documentError.Index, documentError),
+				Severity: SeverityError,
+				Rule:     RuleParseError,
+				Document: documentError.Index,
+				Line:     documentError.Line,
+			})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if len(document.Resource) == 0 {
+			continue
+		}
+		located := LocatedResource{
+			Resource:   document.Resource,
+			Source:     source,
+			Document:   document.Index,
+			node:       document.Node,
+			suppressed: commentSuppressedRules(document.HeadComments),
+		}
+		items, isList := kubernetes.ListItems(document.Resource, knownKind)
+		if !isList {
+			visit(located)
+			continue
+		}
+		for i, item := range items {
+			itemIndex := i
+			itemNode := findNode(document.Node, jsonPointer([]string{"items", strconv.Itoa(i)}))
+			itemResource, ok := item.(map[string]interface{})
+			if !ok {
+				itemError := ValidationResult{
+					Message:  fmt.Sprintf("Item %d of the list in document %d is not an object", i, document.Index),
+					Severity: SeverityError,
+					Rule:     RuleParseError,
+					Document: document.Index,
+					Item:     &itemIndex,
+				}
+				locateResult(&itemError, itemNode)
+				parseError(suppressFindings([]ValidationResult{itemError}, located.suppressed)[0])
+				continue
+			}
+			itemLocated := located
+			itemLocated.Resource = itemResource
+			itemLocated.Item = &itemIndex
+			itemLocated.node = itemNode
+			visit(itemLocated)
+		}
+	}
+}
+
+// finding returns a finding of the resource at the field of the given path, suppressed by its IgnoreAnnotation or IgnoreComment
+func (located LocatedResource) finding(severity Severity, rule string, path []string, message string) ValidationResult {
+	result := ValidationResult{
+		Message:   message,
+		Severity:  severity,
+		Rule:      rule,
+		Name:      kubernetes.GetName(located.Resource),
+		Namespace: kubernetes.GetNamespace(located.Resource),
+		Kind:      kubernetes.GetApiVersionKind(located.Resource),
+		Source:    located.Source,
+		Document:  located.Document,
+		Item:      located.Item,
+		Path:      jsonPointer(path),
+	}
+	locateResult(&result, located.node)
+	results := suppressFindings([]ValidationResult{result}, annotationSuppressedRules(located.Resource))
+	return suppressFindings(results, located.suppressed)[0]
+}
+
+// ApplyOverrides changes the severity of the findings of a SetValidator with the overrides, as NewOverridesValidator does,
+// removing the ignored ones
+func ApplyOverrides(results []ValidationResult, overrides []RuleOverride) []ValidationResult {
+	overridden := make([]ValidationResult, 0, len(results))
+	for _, result := range results {
+		result = applyOverrides(result, overrides)
+		if isFinding(result) || result.Severity == SeveritySuppressed {
+			overridden = append(overridden, result)
+		}
+	}
+	return overridden
+}
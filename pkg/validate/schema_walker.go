@@ -0,0 +1,315 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemaViolation is a single mismatch found between a value and its schema
+type schemaViolation struct {
+	// path to the offending value, relative to the validated resource
+	path []string
+	// value that doesn't match the schema
+	value interface{}
+	err   *openapi3.SchemaError
+}
+
+// collectViolations validates a value against a schema, walking objects and arrays to collect all the violations found in them
+// instead of stopping at the first one like openapi3.Schema.VisitJSON does.
+func collectViolations(schema *openapi3.Schema, value interface{}, path []string) []schemaViolation {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if schema.Type == "" || schema.Type == "object" {
+			return collectObjectViolations(schema, value, path)
+		}
+	case []interface{}:
+		if schema.Type == "" || schema.Type == "array" {
+			return collectArrayViolations(schema, value, path)
+		}
+	}
+	violations := visitViolations(schema, value, path)
+	if len(violations) == 0 {
+		violations = formatViolations(schema, value, path)
+	}
+	if branch := oneOfBranchOfType(schema, value); branch != nil {
+		// report why the value doesn't match the only schema of its type (ie: a quantity with an invalid suffix)
+		// instead of the generic "oneOf" mismatch. The branch is also checked when the "oneOf" matches, as kin-openapi
+		// ignores the Kubernetes formats it doesn't define.
+		if branchViolations := collectRefViolations(branch, value, path); len(branchViolations) > 0 {
+			return branchViolations
+		}
+	}
+	return violations
+}
+
+// oneOfBranchOfType returns the only "oneOf" schema of a plain schema with the type of a scalar value, if there is one
+func oneOfBranchOfType(schema *openapi3.Schema, value interface{}) *openapi3.SchemaRef {
+	if len(schema.OneOf) == 0 || schema.Type != "" || len(schema.AllOf) > 0 || len(schema.AnyOf) > 0 || schema.Not != nil || len(schema.Enum) > 0 {
+		return nil
+	}
+	valueType := ""
+	switch value.(type) {
+	case string:
+		valueType = "string"
+	case bool:
+		valueType = "boolean"
+	case float64:
+		valueType = "number"
+	default:
+		return nil
+	}
+	var match *openapi3.SchemaRef
+	for _, branch := range schema.OneOf {
+		if branch.Value == nil {
+			return nil
+		}
+		branchType := branch.Value.Type
+		if branchType == "integer" {
+			branchType = "number"
+		}
+		if branchType != valueType {
+			continue
+		}
+		if match != nil {
+			return nil
+		}
+		match = branch
+	}
+	return match
+}
+
+func collectObjectViolations(schema *openapi3.Schema, object map[string]interface{}, path []string) []schemaViolation {
+	violations := collectCompositionViolations(schema, object, path)
+
+	constraints := &openapi3.Schema{
+		MinProps: schema.MinProps,
+		MaxProps: schema.MaxProps,
+	}
+	violations = append(violations, visitViolations(constraints, object, path)...)
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		propertyPath := appendPath(path, key)
+		if property := schema.Properties[key]; property != nil {
+			violations = append(violations, collectRefViolations(property, object[key], propertyPath)...)
+			continue
+		}
+		if schema.AdditionalProperties != nil {
+			violations = append(violations, collectRefViolations(schema.AdditionalProperties, object[key], propertyPath)...)
+			continue
+		}
+		if allowed := schema.AdditionalPropertiesAllowed; allowed != nil && !*allowed {
+			violations = append(violations, schemaViolation{
+				path:  path,
+				value: object,
+				err: &openapi3.SchemaError{
+					Value:       object,
+					Schema:      schema,
+					SchemaField: "properties",
+					Reason:      fmt.Sprintf("Property '%s' is unsupported", key),
+				},
+			})
+		}
+	}
+
+	for _, key := range schema.Required {
+		if _, ok := object[key]; !ok {
+			violations = append(violations, schemaViolation{
+				path:  appendPath(path, key),
+				value: object,
+				err: &openapi3.SchemaError{
+					Value:       object,
+					Schema:      schema,
+					SchemaField: "required",
+					Reason:      fmt.Sprintf("Property '%s' is missing", key),
+				},
+			})
+		}
+	}
+	return violations
+}
+
+func collectArrayViolations(schema *openapi3.Schema, array []interface{}, path []string) []schemaViolation {
+	violations := collectCompositionViolations(schema, array, path)
+
+	constraints := &openapi3.Schema{
+		MinItems:    schema.MinItems,
+		MaxItems:    schema.MaxItems,
+		UniqueItems: schema.UniqueItems,
+	}
+	violations = append(violations, visitViolations(constraints, array, path)...)
+
+	if schema.Items != nil {
+		for i, item := range array {
+			violations = append(violations, collectRefViolations(schema.Items, item, appendPath(path, fmt.Sprint(i)))...)
+		}
+	}
+	return violations
+}
+
+// collectCompositionViolations checks the value against the schemas composing the given one ("allOf", "oneOf", "anyOf", "not") and its "enum".
+// The violations of each "allOf" schema are collected separately, while the rest are reported as a single violation when none (or more than one) of their schemas match.
+func collectCompositionViolations(schema *openapi3.Schema, value interface{}, path []string) []schemaViolation {
+	violations := make([]schemaViolation, 0)
+	for _, item := range schema.AllOf {
+		violations = append(violations, collectRefViolations(item, value, path)...)
+	}
+	if len(schema.Enum) == 0 && schema.Not == nil && len(schema.OneOf) == 0 && len(schema.AnyOf) == 0 {
+		return violations
+	}
+	setOperations := &openapi3.Schema{
+		Enum:  schema.Enum,
+		Not:   schema.Not,
+		OneOf: schema.OneOf,
+		AnyOf: schema.AnyOf,
+	}
+	return append(violations, visitViolations(setOperations, value, path)...)
+}
+
+func collectRefViolations(schemaRef *openapi3.SchemaRef, value interface{}, path []string) []schemaViolation {
+	if schemaRef.Value == nil {
+		return []schemaViolation{{
+			path:  path,
+			value: value,
+			err: &openapi3.SchemaError{
+				Value:  value,
+				Reason: fmt.Sprintf("Found unresolved ref: '%s'", schemaRef.Ref),
+			},
+		}}
+	}
+	return collectViolations(schemaRef.Value, value, path)
+}
+
+// visitViolations validates the value with openapi3.Schema.VisitJSON, which reports the first violation found only
+func visitViolations(schema *openapi3.Schema, value interface{}, path []string) []schemaViolation {
+	err := schema.VisitJSON(value)
+	if err == nil {
+		return nil
+	}
+	schemaError, ok := err.(*openapi3.SchemaError)
+	if !ok {
+		schemaError = &openapi3.SchemaError{
+			Value:  value,
+			Schema: schema,
+			Reason: err.Error(),
+		}
+	}
+	// nested errors (ie: from "allOf") hold the offending value themselves
+	for schemaError.Origin != nil {
+		origin, ok := schemaError.Origin.(*openapi3.SchemaError)
+		if !ok {
+			break
+		}
+		schemaError = origin
+	}
+	errorPath := schemaError.JSONPointer()
+	if len(errorPath) > 0 {
+		value = schemaError.Value
+	}
+	return []schemaViolation{{
+		path:  append(appendPath(path), errorPath...),
+		value: value,
+		err:   schemaError,
+	}}
+}
+
+// appendPath returns a copy of path with the given tokens appended, so sibling values don't share the same backing array
+func appendPath(path []string, tokens ...string) []string {
+	newPath := make([]string, 0, len(path)+len(tokens))
+	newPath = append(newPath, path...)
+	return append(newPath, tokens...)
+}
+
+// message describes the violation in the same format as openapi3.SchemaError
+func (violation schemaViolation) message() string {
+	message := violation.err.Reason
+	if message == "" {
+		message = fmt.Sprintf("Doesn't match schema \"%s\"", violation.err.SchemaField)
+	}
+	if len(violation.path) > 0 {
+		message = fmt.Sprintf("Error at \"/%s\":%s", strings.Join(violation.path, "/"), message)
+	}
+	return message
+}
+
+// details describes the failing schema and the offending value, in the same format as openapi3.SchemaError when its details are enabled
+func (violation schemaViolation) details() string {
+	buf := bytes.NewBufferString("\nSchema:\n  ")
+	encoder := json.NewEncoder(buf)
+	encoder.SetIndent("  ", "  ")
+	if err := encoder.Encode(violation.err.Schema); err != nil {
+		fmt.Fprintf(buf, "%v\n", err)
+	}
+	buf.WriteString("\nValue:\n  ")
+	if err := encoder.Encode(violation.value); err != nil {
+		fmt.Fprintf(buf, "%v\n", err)
+	}
+	return buf.String()
+}
+
+// expected describes the value expected by the violated constraint of the schema
+func (violation schemaViolation) expected() string {
+	schema := violation.err.Schema
+	if schema == nil {
+		return ""
+	}
+	switch violation.err.SchemaField {
+	case "type":
+		return schema.Type
+	case "nullable":
+		return "not null"
+	case "enum":
+		return encodeValue(schema.Enum)
+	case "pattern":
+		return schema.Pattern
+	case "format":
+		return schema.Format
+	case "minimum", "exclusiveMinimum":
+		return fmt.Sprintf("%g", *schema.Min)
+	case "maximum", "exclusiveMaximum":
+		return fmt.Sprintf("%g", *schema.Max)
+	case "multipleOf":
+		return fmt.Sprintf("%g", *schema.MultipleOf)
+	case "minLength":
+		return fmt.Sprint(schema.MinLength)
+	case "maxLength":
+		return fmt.Sprint(*schema.MaxLength)
+	case "minItems":
+		return fmt.Sprint(schema.MinItems)
+	case "maxItems":
+		return fmt.Sprint(*schema.MaxItems)
+	case "minProperties":
+		return fmt.Sprint(schema.MinProps)
+	case "maxProperties":
+		return fmt.Sprint(*schema.MaxProps)
+	default:
+		return ""
+	}
+}
+
+// actual returns the offending value encoded as JSON. Objects and arrays are omitted, as they would be too verbose.
+func (violation schemaViolation) actual() string {
+	switch violation.value.(type) {
+	case map[string]interface{}, []interface{}:
+		return ""
+	default:
+		return encodeValue(violation.value)
+	}
+}
+
+func encodeValue(value interface{}) string {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(valueBytes)
+}
@@ -0,0 +1,68 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectViolations(t *testing.T) {
+	notAllowed := false
+	portSchema := openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("port", openapi3.NewInt32Schema().WithMin(1))
+	portSchema.Required = []string{"port"}
+	schema := openapi3.NewObjectSchema().
+		WithProperty("replicas", openapi3.NewIntegerSchema()).
+		WithProperty("protocol", openapi3.NewStringSchema().WithEnum("TCP", "UDP")).
+		WithProperty("ports", openapi3.NewArraySchema().WithItems(portSchema)).
+		WithProperty("targetPort", openapi3.NewOneOfSchema(openapi3.NewStringSchema(), openapi3.NewInt32Schema()))
+	schema.AdditionalPropertiesAllowed = &notAllowed
+	schema.AllOf = []*openapi3.SchemaRef{
+		openapi3.NewSchemaRef("", &openapi3.Schema{Required: []string{"protocol"}}),
+	}
+
+	value := map[string]interface{}{
+		"replicas":   "two",
+		"unexpected": true,
+		"ports": []interface{}{
+			map[string]interface{}{"name": "http", "port": float64(80)},
+			map[string]interface{}{"name": float64(443), "port": float64(0)},
+			map[string]interface{}{"name": "metrics"},
+		},
+		"targetPort": float64(8080),
+	}
+
+	violations := collectViolations(schema, value, []string{})
+
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.message())
+	}
+	assert.Equal(t, []string{
+		"Error at \"/protocol\":Property 'protocol' is missing",
+		"Error at \"/ports/1/name\":Field must be set to string or not be present",
+		"Error at \"/ports/1/port\":Number must be at least 1",
+		"Error at \"/ports/2/port\":Property 'port' is missing",
+		"Error at \"/replicas\":Field must be set to integer or not be present",
+		"Property 'unexpected' is unsupported",
+	}, messages)
+
+	assert.Equal(t, "minimum", violations[2].err.SchemaField)
+	assert.Equal(t, "1", violations[2].expected())
+	assert.Equal(t, "0", violations[2].actual())
+	assert.Equal(t, "integer", violations[4].expected())
+	assert.Equal(t, "\"two\"", violations[4].actual())
+}
+
+func TestCollectViolationsValid(t *testing.T) {
+	schema := openapi3.NewObjectSchema().
+		WithProperty("data", openapi3.NewObjectSchema().WithAdditionalProperties(openapi3.NewStringSchema()))
+
+	violations := collectViolations(schema, map[string]interface{}{
+		"data": map[string]interface{}{"key": "value"},
+	}, []string{})
+
+	assert.Empty(t, violations)
+}
@@ -0,0 +1,76 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// namespacedPathSegment is the segment of the paths of the operations on namespaced resources
+const namespacedPathSegment = "/namespaces/{namespace}/"
+
+// kindScopes tells whether each kind (by "group/version/kind") of OpenAPI V2 specs is namespaced, based on the
+// "x-kubernetes-group-version-kind" of the operations of their paths: the kinds with any operation in a
+// "/namespaces/{namespace}/" path are namespaced, and the rest of the kinds with operations are cluster scoped.
+// Kinds without operations (ie: when the specs have no paths) are left out, as their scope is unknown.
+func kindScopes(paths map[string]*openapi2.PathItem) map[string]bool {
+	scopes := make(map[string]bool)
+	for path, pathItem := range paths {
+		for _, operation := range pathItem.Operations() {
+			addKindScope(scopes, path, operation.ExtensionProps)
+		}
+	}
+	return scopes
+}
+
+// openApi3KindScopes tells whether each kind of OpenAPI V3 specs is namespaced, as kindScopes does
+func openApi3KindScopes(paths openapi3.Paths) map[string]bool {
+	scopes := make(map[string]bool)
+	for path, pathItem := range paths {
+		for _, operation := range pathItem.Operations() {
+			addKindScope(scopes, path, operation.ExtensionProps)
+		}
+	}
+	return scopes
+}
+
+// addKindScope sets the kind of an operation as namespaced if its path is namespaced, or as cluster scoped unless
+// other operations of the kind are namespaced
+func addKindScope(scopes map[string]bool, path string, extensions openapi3.ExtensionProps) {
+	data, ok := extensions.Extensions["x-kubernetes-group-version-kind"].(json.RawMessage)
+	if !ok {
+		return
+	}
+	groupVersionKind := extPropsGroupVersionKind{}
+	if err := json.Unmarshal(data, &groupVersionKind); err != nil {
+		return
+	}
+	kind := groupVersionKind.String()
+	scopes[kind] = scopes[kind] || strings.Contains(path, namespacedPathSegment)
+}
+
+// checkScope reports the namespace of cluster scoped resources, which Kubernetes ignores, and the namespaced resources
+// without namespace when the namespace is required. Resources whose scope is unknown aren't checked.
+func (oeValidator OpenApiValidator) checkScope(result ValidationResult) *ValidationResult {
+	namespaced, ok := oeValidator.namespaced[result.Kind]
+	switch {
+	case !ok:
+		return nil
+	case !namespaced && result.Namespace != "":
+		result.Message = fmt.Sprintf("Kind '%s' is cluster scoped, its namespace '%s' is ignored", result.Kind, result.Namespace)
+		result.Severity = SeverityWarning
+		result.Rule = RuleUnexpectedNamespace
+		result.Path = "/metadata/namespace"
+	case namespaced && result.Namespace == "" && oeValidator.requireNamespace:
+		result.Message = fmt.Sprintf("Kind '%s' is namespaced, its namespace is required", result.Kind)
+		result.Severity = SeverityError
+		result.Rule = RuleMissingNamespace
+		result.Path = "/metadata"
+	default:
+		return nil
+	}
+	return &result
+}
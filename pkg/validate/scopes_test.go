@@ -0,0 +1,181 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/fllaca/scheriff/pkg/kubernetes"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/stretchr/testify/assert"
+)
+
+const scopesTestSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "Kubernetes", "version": "v1.17.0"},
+  "paths": {
+    "/api/v1/namespaces/{namespace}/configmaps": {
+      "get": {"x-kubernetes-group-version-kind": {"group": "", "kind": "ConfigMap", "version": "v1"}},
+      "parameters": [{"in": "path", "name": "namespace", "required": true, "type": "string"}]
+    },
+    "/api/v1/configmaps": {
+      "get": {"x-kubernetes-group-version-kind": {"group": "", "kind": "ConfigMap", "version": "v1"}}
+    },
+    "/apis/rbac.authorization.k8s.io/v1/clusterroles/{name}": {
+      "get": {"x-kubernetes-group-version-kind": {"group": "rbac.authorization.k8s.io", "kind": "ClusterRole", "version": "v1"}},
+      "delete": {"x-kubernetes-group-version-kind": {"group": "rbac.authorization.k8s.io", "kind": "ClusterRole", "version": "v1"}}
+    }
+  },
+  "definitions": {
+    "io.k8s.api.core.v1.ConfigMap": {
+      "type": "object",
+      "properties": {"apiVersion": {"type": "string"}, "kind": {"type": "string"}, "metadata": {"type": "object"}},
+      "x-kubernetes-group-version-kind": [{"group": "", "kind": "ConfigMap", "version": "v1"}]
+    },
+    "io.k8s.api.rbac.v1.ClusterRole": {
+      "type": "object",
+      "properties": {"apiVersion": {"type": "string"}, "kind": {"type": "string"}, "metadata": {"type": "object"}},
+      "x-kubernetes-group-version-kind": [{"group": "rbac.authorization.k8s.io", "kind": "ClusterRole", "version": "v1"}]
+    },
+    "io.k8s.api.core.v1.Binding": {
+      "type": "object",
+      "properties": {"apiVersion": {"type": "string"}, "kind": {"type": "string"}, "metadata": {"type": "object"}},
+      "x-kubernetes-group-version-kind": [{"group": "", "kind": "Binding", "version": "v1"}]
+    }
+  }
+}`
+
+func TestKindScopes(t *testing.T) {
+	swagger2 := &openapi2.Swagger{}
+	assert.NoError(t, json.Unmarshal([]byte(scopesTestSwagger), swagger2))
+
+	assert.Equal(t, map[string]bool{
+		"v1/ConfigMap": true,
+		"rbac.authorization.k8s.io/v1/ClusterRole": false,
+	}, kindScopes(swagger2.Paths))
+	assert.Equal(t, map[string]bool{}, kindScopes(nil))
+}
+
+func TestOpenApiValidatorScopes(t *testing.T) {
+	tests := []struct {
+		name             string
+		requireNamespace bool
+		resource         map[string]interface{}
+		expected         []ValidationResult
+	}{
+		{
+			name:     "namespace of a cluster scoped resource",
+			resource: testResource("rbac.authorization.k8s.io/v1", "ClusterRole", map[string]interface{}{"metadata": map[string]interface{}{"name": "test", "namespace": "example"}}),
+			expected: []ValidationResult{
+				{Message: "Kind 'rbac.authorization.k8s.io/v1/ClusterRole' is cluster scoped, its namespace 'example' is ignored", Severity: SeverityWarning, Rule: RuleUnexpectedNamespace, Name: "test", Namespace: "example", Kind: "rbac.authorization.k8s.io/v1/ClusterRole", Path: "/metadata/namespace"},
+			},
+		},
+		{
+			name:     "cluster scoped resource without namespace",
+			resource: testResource("rbac.authorization.k8s.io/v1", "ClusterRole", nil),
+			expected: []ValidationResult{{Message: "valid", Severity: SeverityOK, Name: "test", Kind: "rbac.authorization.k8s.io/v1/ClusterRole"}},
+		},
+		{
+			name:     "namespaced resource without namespace",
+			resource: testResource("v1", "ConfigMap", nil),
+			expected: []ValidationResult{{Message: "valid", Severity: SeverityOK, Name: "test", Kind: "v1/ConfigMap"}},
+		},
+		{
+			name:             "namespace required",
+			requireNamespace: true,
+			resource:         testResource("v1", "ConfigMap", nil),
+			expected: []ValidationResult{
+				{Message: "Kind 'v1/ConfigMap' is namespaced, its namespace is required", Severity: SeverityError, Rule: RuleMissingNamespace, Name: "test", Kind: "v1/ConfigMap", Path: "/metadata"},
+			},
+		},
+		{
+			name:             "namespace required and set",
+			requireNamespace: true,
+			resource:         testResource("v1", "ConfigMap", map[string]interface{}{"metadata": map[string]interface{}{"name": "test", "namespace": "example"}}),
+			expected:         []ValidationResult{{Message: "valid", Severity: SeverityOK, Name: "test", Namespace: "example", Kind: "v1/ConfigMap"}},
+		},
+		{
+			name:             "unknown scope",
+			requireNamespace: true,
+			resource:         testResource("v1", "Binding", nil),
+			expected:         []ValidationResult{{Message: "valid", Severity: SeverityOK, Name: "test", Kind: "v1/Binding"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validator, err := NewOpenApi2Validator([]byte(scopesTestSwagger), WithRequiredNamespace(test.requireNamespace))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, validator.Validate(test.resource))
+		})
+	}
+}
+
+func TestOpenApiValidatorCrdScopes(t *testing.T) {
+	validator, err := NewOpenApi2Validator([]byte(scopesTestSwagger), WithRequiredNamespace(true))
+	assert.NoError(t, err)
+	crd := func(apiVersion string, kind string, scope string) kubernetes.Resource {
+		return kubernetes.Resource{
+			"apiVersion": apiVersion,
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": "test"},
+			"spec": map[string]interface{}{
+				"group": "example.io",
+				"names": map[string]interface{}{"kind": kind},
+				"scope": scope,
+				"versions": []interface{}{
+					map[string]interface{}{"name": "v1", "served": true, "storage": true, "schema": map[string]interface{}{"openAPIV3Schema": map[string]interface{}{"type": "object"}}},
+				},
+			},
+		}
+	}
+	assert.NoError(t, validator.AddCrdSchemas(crd("apiextensions.k8s.io/v1", "Widget", "Namespaced")))
+	assert.NoError(t, validator.AddCrdSchemas(crd("apiextensions.k8s.io/v1beta1", "ClusterWidget", "Cluster")))
+
+	assert.Equal(t, []ValidationResult{
+		{Message: "Kind 'example.io/v1/Widget' is namespaced, its namespace is required", Severity: SeverityError, Rule: RuleMissingNamespace, Name: "test", Kind: "example.io/v1/Widget", Path: "/metadata"},
+	}, validator.Validate(testResource("example.io/v1", "Widget", nil)))
+	assert.Equal(t, []ValidationResult{
+		{Message: "Kind 'example.io/v1/ClusterWidget' is cluster scoped, its namespace 'example' is ignored", Severity: SeverityWarning, Rule: RuleUnexpectedNamespace, Name: "test", Namespace: "example", Kind: "example.io/v1/ClusterWidget", Path: "/metadata/namespace"},
+	}, validator.Validate(testResource("example.io/v1", "ClusterWidget", map[string]interface{}{"metadata": map[string]interface{}{"name": "test", "namespace": "example"}})))
+}
+
+func TestSchemaBundleScopes(t *testing.T) {
+	validator, err := NewOpenApi2Validator([]byte(scopesTestSwagger))
+	assert.NoError(t, err)
+	bundleBytes := &bytes.Buffer{}
+	assert.NoError(t, validator.Bundle(nil, "checksum").Write(bundleBytes))
+	bundle, err := ReadSchemaBundle(bundleBytes.Bytes())
+	assert.NoError(t, err)
+
+	bundleValidator, err := NewBundleValidator(bundle, WithRequiredNamespace(true))
+
+	assert.NoError(t, err)
+	assert.Equal(t, SeverityWarning, bundleValidator.Validate(testResource("rbac.authorization.k8s.io/v1", "ClusterRole", map[string]interface{}{"metadata": map[string]interface{}{"name": "test", "namespace": "example"}}))[0].Severity)
+	assert.Equal(t, SeverityError, bundleValidator.Validate(testResource("v1", "ConfigMap", nil))[0].Severity)
+}
+
+func TestOpenApi3ValidatorScopes(t *testing.T) {
+	document := []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Kubernetes", "version": "v1.24.0"},
+  "paths": {
+    "/apis/rbac.authorization.k8s.io/v1/clusterroles": {
+      "get": {"responses": {}, "x-kubernetes-group-version-kind": {"group": "rbac.authorization.k8s.io", "kind": "ClusterRole", "version": "v1"}}
+    }
+  },
+  "components": {"schemas": {
+    "io.k8s.api.rbac.v1.ClusterRole": {
+      "type": "object",
+      "properties": {"apiVersion": {"type": "string"}, "kind": {"type": "string"}, "metadata": {"type": "object"}},
+      "x-kubernetes-group-version-kind": [{"group": "rbac.authorization.k8s.io", "kind": "ClusterRole", "version": "v1"}]
+    }
+  }}
+}`)
+	validator, err := NewOpenApi3Validator([][]byte{document})
+	assert.NoError(t, err)
+
+	results := validator.Validate(testResource("rbac.authorization.k8s.io/v1", "ClusterRole", map[string]interface{}{"metadata": map[string]interface{}{"name": "test", "namespace": "example"}}))
+
+	assert.Equal(t, RuleUnexpectedNamespace, results[0].Rule)
+}
@@ -0,0 +1,137 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fllaca/scheriff/pkg/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// SelectorsValidator checks that the selector of the workloads (Deployments, StatefulSets, DaemonSets, ReplicaSets,
+// ReplicationControllers, Jobs and CronJobs) is valid and matches the labels of their pod template, which the Kubernetes
+// API requires but the schemas don't describe. Resources of other kinds are reported as valid.
+type SelectorsValidator struct{}
+
+func NewSelectorsValidator() SelectorsValidator {
+	return SelectorsValidator{}
+}
+
+// Validate validates the selector of a workload. The findings are suppressed by the IgnoreAnnotation as in OpenApiValidator.
+func (selectorsValidator SelectorsValidator) Validate(resource map[string]interface{}) []ValidationResult {
+	return fieldErrorResults(resource, RuleSelectorMismatch, validateSelector(resource))
+}
+
+func validateSelector(resource map[string]interface{}) field.ErrorList {
+	templatePath := podTemplatePath(resource)
+	// bare pods have no selector
+	if len(templatePath) == 0 {
+		return nil
+	}
+	selectorPath := appendPath(templatePath[:len(templatePath)-1], "selector")
+	labelsPath := appendPath(templatePath, "metadata", "labels")
+	selectorValue := kubernetes.GetField(resource, selectorPath...)
+	// when the selector isn't set, it's defaulted to the labels of the template (ie: ReplicationControllers and Jobs)
+	if selectorValue == nil {
+		return nil
+	}
+
+	var selector labels.Selector
+	if groupKind(resource) == "/ReplicationController" {
+		selectorMap := stringMap(selectorValue)
+		if len(selectorMap) == 0 {
+			return nil
+		}
+		selector = labels.SelectorFromSet(selectorMap)
+	} else {
+		labelSelector := &metav1.LabelSelector{}
+		selectorBytes, _ := json.Marshal(selectorValue)
+		if err := json.Unmarshal(selectorBytes, labelSelector); err != nil {
+			// a selector of the wrong type is reported by the schema validation
+			return nil
+		}
+		selectorField := newFieldPath(selectorPath)
+		if errs := metav1validation.ValidateLabelSelector(labelSelector, selectorField); len(errs) > 0 {
+			return errs
+		}
+		if len(labelSelector.MatchLabels)+len(labelSelector.MatchExpressions) == 0 {
+			return field.ErrorList{field.Invalid(selectorField, "{}", "empty selector is invalid")}
+		}
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(labelSelector)
+		if err != nil {
+			return field.ErrorList{field.Invalid(selectorField, string(selectorBytes), err.Error())}
+		}
+	}
+
+	templateLabels := labels.Set(stringMap(kubernetes.GetField(resource, labelsPath...)))
+	if !selector.Matches(templateLabels) {
+		return field.ErrorList{field.Invalid(newFieldPath(labelsPath), templateLabels.String(), fmt.Sprintf("`selector` (%s) does not match template `labels`", selector))}
+	}
+	return nil
+}
+
+// ServiceSelectorsValidator warns about the Services whose selector doesn't match the pods of any of the validated
+// resources (Pods or the pod templates of workloads) in their namespace. Services without selector aren't checked.
+type ServiceSelectorsValidator struct {
+	defaultNamespace string
+}
+
+// NewServiceSelectorsValidator creates a ServiceSelectorsValidator, where the resources without namespace belong to 'defaultNamespace'
+func NewServiceSelectorsValidator(defaultNamespace string) ServiceSelectorsValidator {
+	return ServiceSelectorsValidator{
+		defaultNamespace: defaultNamespace,
+	}
+}
+
+func (validator ServiceSelectorsValidator) Validate(resources []LocatedResource) []ValidationResult {
+	podLabels := make(map[string][]labels.Set)
+	for _, located := range resources {
+		if _, ok := podSpecPaths[groupKind(located.Resource)]; ok {
+			namespace := resourceNamespace(located.Resource, validator.defaultNamespace)
+			labelsPath := appendPath(podTemplatePath(located.Resource), "metadata", "labels")
+			podLabels[namespace] = append(podLabels[namespace], labels.Set(stringMap(kubernetes.GetField(located.Resource, labelsPath...))))
+		}
+	}
+
+	results := make([]ValidationResult, 0)
+	for _, located := range resources {
+		if groupKind(located.Resource) != "/Service" || kubernetes.GetField(located.Resource, "spec", "type") == "ExternalName" {
+			continue
+		}
+		selectorMap := stringMap(kubernetes.GetField(located.Resource, "spec", "selector"))
+		if len(selectorMap) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(selectorMap)
+		matched := false
+		for _, podLabels := range podLabels[resourceNamespace(located.Resource, validator.defaultNamespace)] {
+			if selector.Matches(podLabels) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			results = append(results, located.finding(SeverityWarning, RuleUnmatchedSelector, []string{"spec", "selector"},
+				fmt.Sprintf("Service selector '%s' doesn't match the pods of any workload in the validated resources", selector)))
+		}
+	}
+	return results
+}
+
+// podTemplatePath returns the path to the pod template of a workload, an empty path for Pods, or nil for any other kind
+func podTemplatePath(resource map[string]interface{}) []string {
+	podSpecPath, ok := podSpecPaths[groupKind(resource)]
+	if !ok {
+		return nil
+	}
+	return podSpecPath[:len(podSpecPath)-1]
+}
+
+// newFieldPath converts the tokens of a path to the field path of apimachinery
+func newFieldPath(tokens []string) *field.Path {
+	return field.NewPath(tokens[0], tokens[1:]...)
+}
@@ -0,0 +1,152 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectorsValidator(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource map[string]interface{}
+		expected []ValidationResult
+	}{
+		{
+			name: "matching selector",
+			resource: testResource("apps/v1", "Deployment", map[string]interface{}{"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels":      map[string]interface{}{"app": "web"},
+					"matchExpressions": []interface{}{map[string]interface{}{"key": "tier", "operator": "In", "values": []interface{}{"frontend", "backend"}}},
+				},
+				"template": map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "web", "tier": "frontend", "version": "1.0"}}},
+			}}),
+			expected: []ValidationResult{{Message: "valid", Severity: SeverityOK, Name: "test", Kind: "apps/v1/Deployment"}},
+		},
+		{
+			name: "selector not matching the template labels",
+			resource: testResource("apps/v1", "StatefulSet", map[string]interface{}{"spec": map[string]interface{}{
+				"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "db"}},
+				"template": map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "database"}}},
+			}}),
+			expected: []ValidationResult{
+				{Message: "spec.template.metadata.labels: Invalid value: \"app=database\": `selector` (app=db) does not match template `labels`", Severity: SeverityError, Rule: RuleSelectorMismatch, Name: "test", Kind: "apps/v1/StatefulSet", Path: "/spec/template/metadata/labels", Actual: "\"app=database\""},
+			},
+		},
+		{
+			name: "empty selector",
+			resource: testResource("apps/v1", "DaemonSet", map[string]interface{}{"spec": map[string]interface{}{
+				"selector": map[string]interface{}{},
+				"template": map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "agent"}}},
+			}}),
+			expected: []ValidationResult{
+				{Message: "spec.selector: Invalid value: \"{}\": empty selector is invalid", Severity: SeverityError, Rule: RuleSelectorMismatch, Name: "test", Kind: "apps/v1/DaemonSet", Path: "/spec/selector", Actual: "\"{}\""},
+			},
+		},
+		{
+			name: "invalid selector",
+			resource: testResource("apps/v1", "ReplicaSet", map[string]interface{}{"spec": map[string]interface{}{
+				"selector": map[string]interface{}{"matchExpressions": []interface{}{map[string]interface{}{"key": "app", "operator": "In"}}},
+				"template": map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "web"}}},
+			}}),
+			expected: []ValidationResult{
+				{Message: "spec.selector.matchExpressions[0].values: Required value: must be specified when `operator` is 'In' or 'NotIn'", Severity: SeverityError, Rule: RuleSelectorMismatch, Name: "test", Kind: "apps/v1/ReplicaSet", Path: "/spec/selector/matchExpressions/0/values"},
+			},
+		},
+		{
+			name: "replication controller",
+			resource: testResource("v1", "ReplicationController", map[string]interface{}{"spec": map[string]interface{}{
+				"selector": map[string]interface{}{"app": "web"},
+				"template": map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "api"}}},
+			}}),
+			expected: []ValidationResult{
+				{Message: "spec.template.metadata.labels: Invalid value: \"app=api\": `selector` (app=web) does not match template `labels`", Severity: SeverityError, Rule: RuleSelectorMismatch, Name: "test", Kind: "v1/ReplicationController", Path: "/spec/template/metadata/labels", Actual: "\"app=api\""},
+			},
+		},
+		{
+			name: "cron job",
+			resource: testResource("batch/v1beta1", "CronJob", map[string]interface{}{"spec": map[string]interface{}{
+				"jobTemplate": map[string]interface{}{"spec": map[string]interface{}{
+					"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"job": "backup"}},
+					"template": map[string]interface{}{"metadata": map[string]interface{}{}},
+				}},
+			}}),
+			expected: []ValidationResult{
+				{Message: "spec.jobTemplate.spec.template.metadata.labels: Invalid value: \"\": `selector` (job=backup) does not match template `labels`", Severity: SeverityError, Rule: RuleSelectorMismatch, Name: "test", Kind: "batch/v1beta1/CronJob", Path: "/spec/jobTemplate/spec/template/metadata/labels", Actual: "\"\""},
+			},
+		},
+		{
+			name: "defaulted selector",
+			resource: testResource("batch/v1", "Job", map[string]interface{}{"spec": map[string]interface{}{
+				"template": map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "migration"}}},
+			}}),
+			expected: []ValidationResult{{Message: "valid", Severity: SeverityOK, Name: "test", Kind: "batch/v1/Job"}},
+		},
+		{
+			name:     "not a workload",
+			resource: testResource("v1", "Service", map[string]interface{}{"spec": map[string]interface{}{"selector": map[string]interface{}{"app": "web"}}}),
+			expected: []ValidationResult{{Message: "valid", Severity: SeverityOK, Name: "test", Kind: "v1/Service"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, NewSelectorsValidator().Validate(test.resource))
+		})
+	}
+}
+
+func TestServiceSelectorsValidator(t *testing.T) {
+	resources := readTestResources(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+        tier: frontend
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+  namespace: tools
+  labels:
+    app: debug
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: debug
+spec:
+  selector:
+    app: debug
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: external
+spec:
+  type: ExternalName
+  externalName: example.com
+`)
+
+	results := NewServiceSelectorsValidator("default").Validate(resources)
+
+	assert.Equal(t, []ValidationResult{
+		{Message: "Service selector 'app=debug' doesn't match the pods of any workload in the validated resources", Severity: SeverityWarning, Rule: RuleUnmatchedSelector, Name: "debug", Kind: "v1/Service", Source: "test.yaml", Document: 3, Path: "/spec/selector", Line: 36, Column: 3},
+	}, results)
+}
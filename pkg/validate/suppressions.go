@@ -0,0 +1,82 @@
+package validate
+
+import (
+	"strings"
+
+	"github.com/fllaca/scheriff/pkg/kubernetes"
+)
+
+const (
+	// IgnoreAnnotation is the annotation of the resources whose findings are suppressed, its value is a comma separated
+	// list of rules (ie: "schema,unknown-kind"), or empty to suppress all of them
+	IgnoreAnnotation = "scheriff.io/ignore"
+	// IgnoreComment is the comment that suppresses the findings of the document it's placed above, optionally followed
+	// by the list of rules as in IgnoreAnnotation (ie: "# scheriff:ignore schema,unknown-kind")
+	IgnoreComment = "scheriff:ignore"
+)
+
+// suppressedRules is the set of rules whose findings are suppressed, an empty set suppresses all of them
+type suppressedRules map[string]bool
+
+// parseSuppressedRules parses a comma separated list of rules. Rules can be given by their id (ie: "schema-violation")
+// or by the first word of their id (ie: "schema").
+func parseSuppressedRules(value string) suppressedRules {
+	rules := suppressedRules{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		// unknown rules are kept too, so that the set isn't empty (suppressing all of them)
+		rules[name] = true
+		for rule := range RuleDescriptions {
+			if strings.HasPrefix(rule, name+"-") {
+				rules[rule] = true
+			}
+		}
+	}
+	return rules
+}
+
+func (rules suppressedRules) suppresses(rule string) bool {
+	return len(rules) == 0 || rules[rule]
+}
+
+// annotationSuppressedRules returns the rules suppressed by the IgnoreAnnotation of the resource, nil if it's not annotated
+func annotationSuppressedRules(resource map[string]interface{}) suppressedRules {
+	value, ok := kubernetes.GetAnnotation(resource, IgnoreAnnotation)
+	if !ok {
+		return nil
+	}
+	return parseSuppressedRules(value)
+}
+
+// commentSuppressedRules returns the rules suppressed by an IgnoreComment among the comments above a document, nil if there is none
+func commentSuppressedRules(comments []string) suppressedRules {
+	for _, comment := range comments {
+		if comment != IgnoreComment && !strings.HasPrefix(comment, IgnoreComment+" ") && !strings.HasPrefix(comment, IgnoreComment+":") {
+			continue
+		}
+		return parseSuppressedRules(strings.TrimLeft(strings.TrimPrefix(comment, IgnoreComment), ": "))
+	}
+	return nil
+}
+
+// suppressFindings sets the SeveritySuppressed severity to the errors and warnings of the suppressed rules,
+// so they are reported apart from the other findings
+func suppressFindings(results []ValidationResult, rules suppressedRules) []ValidationResult {
+	if rules == nil {
+		return results
+	}
+	for i := range results {
+		if isFinding(results[i]) && rules.suppresses(results[i].Rule) {
+			results[i].Severity = SeveritySuppressed
+		}
+	}
+	return results
+}
+
+// isFinding tells if a result is an error or a warning
+func isFinding(result ValidationResult) bool {
+	return result.Severity == SeverityError || result.Severity == SeverityWarning
+}
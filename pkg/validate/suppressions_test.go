@@ -0,0 +1,79 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSuppressedRules(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected suppressedRules
+	}{
+		{value: "", expected: suppressedRules{}},
+		{value: "schema-violation", expected: suppressedRules{RuleSchemaViolation: true}},
+		{value: "schema, unknown-kind", expected: suppressedRules{"schema": true, RuleSchemaViolation: true, RuleUnknownKind: true}},
+		{value: "typo", expected: suppressedRules{"typo": true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseSuppressedRules(test.value))
+		})
+	}
+}
+
+func TestCommentSuppressedRules(t *testing.T) {
+	assert.Nil(t, commentSuppressedRules([]string{"some comment", "scheriff:ignored"}))
+	assert.Equal(t, suppressedRules{}, commentSuppressedRules([]string{"some comment", "scheriff:ignore"}))
+	assert.Equal(t, suppressedRules{"unknown": true, RuleUnknownKind: true}, commentSuppressedRules([]string{"scheriff:ignore unknown"}))
+	assert.Equal(t, suppressedRules{RuleSchemaViolation: true}, commentSuppressedRules([]string{"scheriff:ignore: schema-violation"}))
+}
+
+func TestOpenApiValidatorIgnoreAnnotation(t *testing.T) {
+	validator, err := NewOpenApi2Validator([]byte(deprecationsTestSwagger), WithDeprecationChecks(true))
+	assert.NoError(t, err)
+
+	annotated := func(apiVersion string, kind string, value string) map[string]interface{} {
+		annotatedResource := testResource(apiVersion, kind, nil)
+		annotatedResource["metadata"] = map[string]interface{}{"name": "test", "annotations": map[string]interface{}{IgnoreAnnotation: value}}
+		annotatedResource["spec"] = map[string]interface{}{}
+		return annotatedResource
+	}
+
+	tests := []struct {
+		name     string
+		resource map[string]interface{}
+		expected []ValidationResult
+	}{
+		{
+			name:     "suppressed rule",
+			resource: annotated("batch/v1beta1", "CronJob", "schema"),
+			expected: []ValidationResult{
+				{Message: "Kind 'batch/v1beta1/CronJob' deprecated in 1.21, removed in 1.25, migrate to batch/v1", Severity: SeverityWarning, Rule: RuleDeprecatedApi, Name: "test", Kind: "batch/v1beta1/CronJob"},
+				{Message: "Property 'spec' is unsupported", Severity: SeveritySuppressed, Rule: RuleSchemaViolation, Name: "test", Kind: "batch/v1beta1/CronJob", Constraint: "properties"},
+			},
+		},
+		{
+			name:     "all rules suppressed",
+			resource: annotated("example.io/v1", "UnknownCRD", ""),
+			expected: []ValidationResult{
+				{Message: "Kind 'example.io/v1/UnknownCRD' not found in schema", Severity: SeveritySuppressed, Rule: RuleUnknownKind, Name: "test", Kind: "example.io/v1/UnknownCRD"},
+			},
+		},
+		{
+			name:     "valid resources are not changed",
+			resource: testResource("batch/v1", "CronJob", nil),
+			expected: []ValidationResult{
+				{Message: "Kind 'batch/v1/CronJob' not found in schema", Severity: SeverityWarning, Rule: RuleUnknownKind, Name: "test", Kind: "batch/v1/CronJob"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, validator.Validate(test.resource))
+		})
+	}
+}
@@ -1,30 +1,102 @@
 package validate
 
+import "io"
+
 type Severity string
 
 const (
 	SeverityError   Severity = "ERROR"
 	SeverityWarning Severity = "WARN"
 	SeverityOK      Severity = "OK"
+	// SeveritySuppressed is the severity of the errors and warnings suppressed by an annotation or a comment (see IgnoreAnnotation)
+	SeveritySuppressed Severity = "SUPPRESSED"
+)
+
+// Rules classify the findings of the validation, so they can be identified (ie: in SARIF reports)
+const (
+	RuleParseError      = "parse-error"
+	RuleUnknownKind     = "unknown-kind"
+	RuleSchemaViolation = "schema-violation"
+	RuleDeprecatedApi   = "deprecated-api"
+	RuleRemovedApi      = "removed-api"
+	// RuleUnexpectedNamespace and RuleMissingNamespace are applied by the OpenApiValidator to the kinds whose scope is known
+	RuleUnexpectedNamespace = "unexpected-namespace"
+	RuleMissingNamespace    = "missing-namespace"
+	// RuleMetadataViolation is applied by the MetadataValidator
+	RuleMetadataViolation = "metadata-violation"
+	// RuleMissingReference is applied by the ReferencesValidator
+	RuleMissingReference = "missing-reference"
+	// RuleDuplicateResource is applied by the DuplicatesValidator
+	RuleDuplicateResource = "duplicate-resource"
+	// RuleSelectorMismatch is applied by the SelectorsValidator
+	RuleSelectorMismatch = "selector-mismatch"
+	// RuleUnmatchedSelector is applied by the ServiceSelectorsValidator
+	RuleUnmatchedSelector = "unmatched-selector"
 )
 
+// RuleDescriptions holds a short description of each of the Rules
+var RuleDescriptions = map[string]string{
+	RuleParseError:          "The document cannot be parsed as a Kubernetes resource",
+	RuleUnknownKind:         "The kind of the resource is not defined in the schemas",
+	RuleSchemaViolation:     "The resource doesn't match the schema of its kind",
+	RuleDeprecatedApi:       "The API version of the resource is deprecated",
+	RuleRemovedApi:          "The API version of the resource has been removed",
+	RuleMetadataViolation:   "The name, namespace, labels or annotations of the resource are rejected by the Kubernetes API",
+	RuleMissingReference:    "The resource references an object that isn't defined in the validated resources",
+	RuleDuplicateResource:   "The resource is defined more than once in the validated resources",
+	RuleSelectorMismatch:    "The selector of the workload is invalid or doesn't match the labels of its pod template",
+	RuleUnmatchedSelector:   "The selector of the Service doesn't match the pods of any of the validated resources",
+	RuleUnexpectedNamespace: "The resource is cluster scoped, but it sets a namespace",
+	RuleMissingNamespace:    "The resource is namespaced, but it doesn't set its namespace",
+}
+
+// StdinSource is the ValidationResult source of resources read from the standard input
+const StdinSource = "stdin"
+
 type ResourceValidator interface {
-	Validate(resource map[string]interface{}) ValidationResult
+	// Validate returns a single OK result when the resource is valid, or one result per finding otherwise
+	Validate(resource map[string]interface{}) []ValidationResult
 }
 
 type ValidationResult struct {
 	// Message holds a brief description of the validation result
-	Message string
+	Message string `json:"message"`
 	// Severity specifies if the validation is OK/ERROR/WARNING
-	Severity Severity
+	Severity Severity `json:"severity"`
+	// Rule identifies the kind of finding for ERROR/WARNING results
+	Rule string `json:"rule,omitempty"`
 	// Name of the validated resourcce
-	Name string
+	Name string `json:"name,omitempty"`
 	// Namespace of the validated resourcce
-	Namespace string
+	Namespace string `json:"namespace,omitempty"`
 	// Kind of the validated resourcce
-	Kind string
+	Kind string `json:"kind,omitempty"`
+	// Source is the file (or stdin) the validated resource was read from
+	Source string `json:"source,omitempty"`
+	// Schema identifies the schema the resource was validated against, only set when validating against several schemas
+	Schema string `json:"schema,omitempty"`
+	// Document is the index of the YAML document within the source that contains the validated resource
+	Document int `json:"document"`
+	// Item is the index of the validated resource within the items of the List in the document, nil if the document isn't a List
+	Item *int `json:"item,omitempty"`
+	// Path is the JSON pointer to the field of the resource that caused the finding, if any
+	Path string `json:"path,omitempty"`
+	// Line of the source where the finding is located (starting at 1), or 0 when unknown
+	Line int `json:"line,omitempty"`
+	// Column of the source where the finding is located (starting at 1), or 0 when unknown
+	Column int `json:"column,omitempty"`
+	// Constraint is the schema keyword violated by the resource (ie: "type", "required", "pattern"...)
+	Constraint string `json:"constraint,omitempty"`
+	// Expected describes the value required by the violated constraint (ie: the expected type)
+	Expected string `json:"expected,omitempty"`
+	// Actual is the offending value encoded as JSON, omitted for objects and arrays
+	Actual string `json:"actual,omitempty"`
 }
 
 type FileValidator interface {
-	Validate(data []byte) []ValidationResult
+	// Validate validates the documents read from 'reader', it only returns an error when the reader fails
+	Validate(reader io.Reader) ([]ValidationResult, error)
+	// ValidateResources validates the documents read from 'reader' as Validate does, and returns the resources read too
+	// (located in 'source'), so that they can be validated as a set without reading the source again
+	ValidateResources(source string, reader io.Reader) ([]ValidationResult, []LocatedResource, error)
 }
@@ -1,46 +1,76 @@
 package validate
 
 import (
-	"bytes"
-	"fmt"
-	"sigs.k8s.io/yaml"
+	"io"
+
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
 type YamlFileValidator struct {
 	resourceValidator ResourceValidator
+	knownKind         func(apiVersionKind string) bool
 }
 
-func NewYamlFileValidator(resourceValidator ResourceValidator) YamlFileValidator {
+// NewYamlFileValidator creates a YamlFileValidator, where 'knownKind' tells which kinds have a schema to find the typed
+// lists (see kubernetes.ListItems), ie: OpenApiValidator.HasKind
+func NewYamlFileValidator(resourceValidator ResourceValidator, knownKind func(apiVersionKind string) bool) YamlFileValidator {
 	return YamlFileValidator{
 		resourceValidator: resourceValidator,
+		knownKind:         knownKind,
 	}
 }
 
-func (yamlValidator YamlFileValidator) Validate(fileBytes []byte) []ValidationResult {
-	result := make([]ValidationResult, 0)
-	documentsBytes := bytes.Split(fileBytes, []byte("\n---\n"))
-	for docIndex, documentBytes := range documentsBytes {
-		k8sResource, err := parseResource(documentBytes)
-		if err != nil {
-			result = append(result, ValidationResult{
-				Message:  fmt.Sprintf("Error parsing k8s resource from document %d: %s\n", docIndex, err),
-				Severity: SeverityError,
-			})
-			continue
+// Validate validates every document of a YAML stream, as it is read. The findings of the rules listed in an IgnoreComment
+// above a document are suppressed. The error is only returned when the stream can't be read.
+func (yamlValidator YamlFileValidator) Validate(reader io.Reader) ([]ValidationResult, error) {
+	results, _, err := yamlValidator.validate("", reader, false)
+	return results, err
+}
+
+// ValidateResources validates a YAML stream as Validate does, returning the resources read from 'source' too
+func (yamlValidator YamlFileValidator) ValidateResources(source string, reader io.Reader) ([]ValidationResult, []LocatedResource, error) {
+	return yamlValidator.validate(source, reader, true)
+}
+
+// validate validates the resources of a YAML stream, keeping them only when 'collect' is set so that large streams
+// aren't held in memory otherwise
+func (yamlValidator YamlFileValidator) validate(source string, reader io.Reader, collect bool) ([]ValidationResult, []LocatedResource, error) {
+	results := make([]ValidationResult, 0)
+	var resources []LocatedResource
+	if collect {
+		resources = make([]LocatedResource, 0)
+	}
+	err := decodeResources(source, reader, yamlValidator.knownKind, func(located LocatedResource) {
+		resourceResults := yamlValidator.validateResource(located.Resource, located.Document, located.Item, located.node)
+		results = append(results, suppressFindings(resourceResults, located.suppressed)...)
+		if collect {
+			resources = append(resources, located)
 		}
-		if len(k8sResource) == 0 {
-			continue
+	}, func(parseError ValidationResult) {
+		results = append(results, parseError)
+	})
+	return results, resources, err
+}
+
+// validateResource validates a resource of a document (or an item of a List), given the node of the resource in the document
+func (yamlValidator YamlFileValidator) validateResource(resource map[string]interface{}, document int, item *int, node *yamlv3.Node) []ValidationResult {
+	results := yamlValidator.resourceValidator.Validate(resource)
+	for i := range results {
+		results[i].Document = document
+		results[i].Item = item
+		if results[i].Severity != SeverityOK {
+			locateResult(&results[i], node)
 		}
-		result = append(result, yamlValidator.resourceValidator.Validate(k8sResource))
 	}
-	return result
+	return results
 }
 
-func parseResource(resourceBytes []byte) (map[string]interface{}, error) {
-	var resource map[string]interface{}
-	err := yaml.Unmarshal(resourceBytes, &resource)
-	if err != nil {
-		return nil, err
+// locateResult sets the line and column in the file of the field pointed by the result path
+func locateResult(result *ValidationResult, root *yamlv3.Node) {
+	line, column := findPosition(root, result.Path)
+	if line == 0 {
+		return
 	}
-	return resource, nil
+	result.Line = line
+	result.Column = column
 }